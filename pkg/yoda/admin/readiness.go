@@ -0,0 +1,70 @@
+package admin
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Check reports why a subsystem is not ready, or a detail worth showing, such
+// as a degraded mode, while it is.
+type Check func() (detail string, err error)
+
+// Readiness reports ready only once every registered check passes.
+type Readiness struct {
+	mu     sync.RWMutex
+	checks map[string]Check
+}
+
+type ReadinessReport struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"`
+}
+
+func NewReadiness() *Readiness {
+	return &Readiness{checks: map[string]Check{}}
+}
+
+func (r *Readiness) AddCheck(name string, check func() error) {
+	r.AddDetailedCheck(name, func() (string, error) {
+		return "", check()
+	})
+}
+
+func (r *Readiness) AddDetailedCheck(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = check
+}
+
+func (r *Readiness) Report() ReadinessReport {
+	r.mu.RLock()
+	checks := make(map[string]Check, len(r.checks))
+	for name, check := range r.checks {
+		checks[name] = check
+	}
+	r.mu.RUnlock()
+
+	report := ReadinessReport{Ready: true, Checks: map[string]string{}}
+	for name, check := range checks {
+		detail, err := check()
+		switch {
+		case err != nil:
+			report.Ready = false
+			report.Checks[name] = err.Error()
+		case detail != "":
+			report.Checks[name] = detail
+		default:
+			report.Checks[name] = "ok"
+		}
+	}
+	return report
+}
+
+func (r *Readiness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	report := r.Report()
+	code := http.StatusOK
+	if !report.Ready {
+		code = http.StatusServiceUnavailable
+	}
+	WriteJSON(w, code, report)
+}
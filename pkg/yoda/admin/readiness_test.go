@@ -0,0 +1,84 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name       string
+		checks     map[string]Check
+		wantCode   int
+		wantChecks map[string]string
+	}{
+		{
+			name: "every subsystem ready",
+			checks: map[string]Check{
+				"cache":  func() (string, error) { return "", nil },
+				"client": func() (string, error) { return "", nil },
+			},
+			wantCode:   http.StatusOK,
+			wantChecks: map[string]string{"cache": "ok", "client": "ok"},
+		},
+		{
+			name: "one subsystem not ready",
+			checks: map[string]Check{
+				"cache":  func() (string, error) { return "", errors.New("not synced") },
+				"client": func() (string, error) { return "", nil },
+			},
+			wantCode:   http.StatusServiceUnavailable,
+			wantChecks: map[string]string{"cache": "not synced", "client": "ok"},
+		},
+		{
+			name: "ready with a detail",
+			checks: map[string]Check{
+				"client": func() (string, error) { return "passthrough", nil },
+			},
+			wantCode:   http.StatusOK,
+			wantChecks: map[string]string{"client": "passthrough"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReadiness()
+			for name, check := range tt.checks {
+				r.AddDetailedCheck(name, check)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %v, want %v", w.Code, tt.wantCode)
+			}
+			var report ReadinessReport
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+				t.Fatal(err)
+			}
+			if report.Ready != (tt.wantCode == http.StatusOK) || !reflect.DeepEqual(report.Checks, tt.wantChecks) {
+				t.Errorf("report = %+v, want checks %v", report, tt.wantChecks)
+			}
+		})
+	}
+}
+
+func TestReadinessToggles(t *testing.T) {
+	ready := false
+	r := NewReadiness()
+	r.AddCheck("reconciler", func() error {
+		if !ready {
+			return errors.New("not run yet")
+		}
+		return nil
+	})
+	if r.Report().Ready {
+		t.Fatal("ready before the reconciler ran")
+	}
+	ready = true
+	if !r.Report().Ready {
+		t.Fatal("not ready after the reconciler ran")
+	}
+}
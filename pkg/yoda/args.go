@@ -0,0 +1,356 @@
+package yoda
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/ledger"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/metrics"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/score"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/scvcache"
+)
+
+const (
+	DefaultRejectionWindowMinutes       = 60
+	DefaultDeadlineHorizonMinutes       = 60
+	DefaultPublishIntervalSeconds       = 30
+	DefaultMaxDecisionRetries           = 3
+	DefaultScvCacheTTLSeconds           = 5
+	DefaultOomQuarantineWindowMinutes   = 30
+	DefaultOomQuarantineCooldownMinutes = 60
+	DefaultPlacementHistoryTTLMinutes   = 24 * 60
+	DefaultScoreHistorySize             = 60
+)
+
+type Args struct {
+	KubeConfig                   string                        `json:"kubeconfig,omitempty"`
+	Master                       string                        `json:"master,omitempty"`
+	ScvClientQPS                 float32                       `json:"scvClientQPS,omitempty"`
+	ScvClientBurst               int                           `json:"scvClientBurst,omitempty"`
+	AdminAddress                 string                        `json:"adminAddress,omitempty"`
+	RejectionWindowMinutes       int                           `json:"rejectionWindowMinutes,omitempty"`
+	HomogeneityWeight            uint64                        `json:"homogeneityWeight,omitempty"`
+	CardScoreAggregation         string                        `json:"cardScoreAggregation,omitempty"`
+	CardScoreTopK                int                           `json:"cardScoreTopK,omitempty"`
+	StrictGpuNodeIsolation       bool                          `json:"strictGpuNodeIsolation,omitempty"`
+	UnrequestedMeansNoGpu        bool                          `json:"unrequestedMeansNoGpu,omitempty"`
+	DeadlineUrgencyWeight        uint64                        `json:"deadlineUrgencyWeight,omitempty"`
+	DeadlineHorizonMinutes       int                           `json:"deadlineHorizonMinutes,omitempty"`
+	PublishExtendedResources     bool                          `json:"publishExtendedResources,omitempty"`
+	PublishIntervalSeconds       int                           `json:"publishIntervalSeconds,omitempty"`
+	RequireScratchInfo           bool                          `json:"requireScratchInfo,omitempty"`
+	PowerStateWeight             uint64                        `json:"powerStateWeight,omitempty"`
+	PowerSaving                  bool                          `json:"powerSaving,omitempty"`
+	ArchMatchWeight              uint64                        `json:"archMatchWeight,omitempty"`
+	MaintenanceMode              bool                          `json:"maintenanceMode,omitempty"`
+	BigNodeReservationWeight     uint64                        `json:"bigNodeReservationWeight,omitempty"`
+	NeutralScore                 float64                       `json:"neutralScore,omitempty"`
+	RespectScaleDown             bool                          `json:"respectScaleDown,omitempty"`
+	ScoringStrategy              string                        `json:"scoringStrategy,omitempty"`
+	ResidentModelWeight          uint64                        `json:"residentModelWeight,omitempty"`
+	MaxDecisionRetries           int                           `json:"maxDecisionRetries,omitempty"`
+	ColdSpareCount               int                           `json:"coldSpareCount,omitempty"`
+	CheckpointAffinityWeight     uint64                        `json:"checkpointAffinityWeight,omitempty"`
+	CycleLatencySLOms            int                           `json:"cycleLatencySLOms,omitempty"`
+	CpuRatioWeight               uint64                        `json:"cpuRatioWeight,omitempty"`
+	EnforceCpuRatio              bool                          `json:"enforceCpuRatio,omitempty"`
+	BandwidthContentionWeight    uint64                        `json:"bandwidthContentionWeight,omitempty"`
+	FilterReadMode               string                        `json:"filterReadMode,omitempty"`
+	ScoreReadMode                string                        `json:"scoreReadMode,omitempty"`
+	ReserveReadMode              string                        `json:"reserveReadMode,omitempty"`
+	ScvCacheTTLSeconds           int                           `json:"scvCacheTTLSeconds,omitempty"`
+	OomQuarantineThreshold       int                           `json:"oomQuarantineThreshold,omitempty"`
+	OomQuarantineWindowMinutes   int                           `json:"oomQuarantineWindowMinutes,omitempty"`
+	OomQuarantineCooldownMinutes int                           `json:"oomQuarantineCooldownMinutes,omitempty"`
+	AttestationWeight            uint64                        `json:"attestationWeight,omitempty"`
+	OnScvClientFailure           string                        `json:"onScvClientFailure,omitempty"`
+	ThermalDerateCurve           string                        `json:"thermalDerateCurve,omitempty"`
+	RecentlyFreedWeight          uint64                        `json:"recentlyFreedWeight,omitempty"`
+	PredicateSeverity            map[string]string             `json:"predicateSeverity,omitempty"`
+	PlacementHistoryWeight       uint64                        `json:"placementHistoryWeight,omitempty"`
+	PlacementHistoryTTLMinutes   int                           `json:"placementHistoryTTLMinutes,omitempty"`
+	DriftToleranceMB             uint64                        `json:"driftToleranceMB,omitempty"`
+	PcieSwitchWeight             uint64                        `json:"pcieSwitchWeight,omitempty"`
+	RequirementParsers           []string                      `json:"requirementParsers,omitempty"`
+	RequirementProfiles          map[string]filter.Requirement `json:"requirementProfiles,omitempty"`
+	IdleNodePenaltyWeight        uint64                        `json:"idleNodePenaltyWeight,omitempty"`
+	PreferConsolidation          bool                          `json:"preferConsolidation,omitempty"`
+	MinScvAgentVersion           string                        `json:"minScvAgentVersion,omitempty"`
+	ModeMatchWeight              uint64                        `json:"modeMatchWeight,omitempty"`
+	RecordScoreHistory           bool                          `json:"recordScoreHistory,omitempty"`
+	ScoreHistorySize             int                           `json:"scoreHistorySize,omitempty"`
+	ImageDefaults                map[string]string             `json:"imageDefaults,omitempty"`
+	SidecarWarmWeight            uint64                        `json:"sidecarWarmWeight,omitempty"`
+	MaxPodGpuCards               int                           `json:"maxPodGpuCards,omitempty"`
+	MaxPodGpuMemory              string                        `json:"maxPodGpuMemory,omitempty"`
+	VendorBalanceWeight          uint64                        `json:"vendorBalanceWeight,omitempty"`
+	MissingDataPolicy            string                        `json:"missingDataPolicy,omitempty"`
+	ClockUniformityWeight        uint64                        `json:"clockUniformityWeight,omitempty"`
+	AllowedNamespaces            []string                      `json:"allowedNamespaces,omitempty"`
+	RespectDrain                 string                        `json:"respectDrain,omitempty"`
+	SharedCardMemoryGapMB        uint64                        `json:"sharedCardMemoryGapMB,omitempty"`
+	KernelErrorWeight            uint64                        `json:"kernelErrorWeight,omitempty"`
+	MaxBonusContribution         uint64                        `json:"maxBonusContribution,omitempty"`
+	MigRepartitionWeight         uint64                        `json:"migRepartitionWeight,omitempty"`
+	InitContainerGpuPolicy       string                        `json:"initContainerGpuPolicy,omitempty"`
+	InteractiveIsolationWeight   uint64                        `json:"interactiveIsolationWeight,omitempty"`
+	FirstConsumerPenalty         uint64                        `json:"firstConsumerPenalty,omitempty"`
+	ScvGroupVersionKind          string                        `json:"scvGroupVersionKind,omitempty"`
+	ClockLockWeight              uint64                        `json:"clockLockWeight,omitempty"`
+	PerNodeScoreDeadlineMs       int                           `json:"perNodeScoreDeadlineMs,omitempty"`
+	ExternalScoreWeight          uint64                        `json:"externalScoreWeight,omitempty"`
+	MpsReuseWeight               uint64                        `json:"mpsReuseWeight,omitempty"`
+	EqualScoreFallback           string                        `json:"equalScoreFallback,omitempty"`
+	EqualScoreFallbackSeed       int64                         `json:"equalScoreFallbackSeed,omitempty"`
+	SysmemPerGpuMode             string                        `json:"sysmemPerGpuMode,omitempty"`
+
+	thermalCurve score.ThermalCurve
+}
+
+func (a *Args) SetDefaults() {
+	if a.ScvClientQPS <= 0 {
+		if a.ScvClientQPS < 0 {
+			klog.Warningf("invalid scvClientQPS %v, falling back to %v", a.ScvClientQPS, rest.DefaultQPS)
+		}
+		a.ScvClientQPS = rest.DefaultQPS
+	}
+	if a.ScvClientBurst <= 0 {
+		if a.ScvClientBurst < 0 {
+			klog.Warningf("invalid scvClientBurst %v, falling back to %v", a.ScvClientBurst, rest.DefaultBurst)
+		}
+		a.ScvClientBurst = rest.DefaultBurst
+	}
+	if a.RejectionWindowMinutes <= 0 {
+		a.RejectionWindowMinutes = DefaultRejectionWindowMinutes
+	}
+	if a.DeadlineHorizonMinutes <= 0 {
+		a.DeadlineHorizonMinutes = DefaultDeadlineHorizonMinutes
+	}
+	if a.PublishIntervalSeconds <= 0 {
+		a.PublishIntervalSeconds = DefaultPublishIntervalSeconds
+	}
+	if a.MaxDecisionRetries <= 0 {
+		a.MaxDecisionRetries = DefaultMaxDecisionRetries
+	}
+	if a.ScvCacheTTLSeconds <= 0 {
+		a.ScvCacheTTLSeconds = DefaultScvCacheTTLSeconds
+	}
+	if a.OomQuarantineWindowMinutes <= 0 {
+		a.OomQuarantineWindowMinutes = DefaultOomQuarantineWindowMinutes
+	}
+	if a.OomQuarantineCooldownMinutes <= 0 {
+		a.OomQuarantineCooldownMinutes = DefaultOomQuarantineCooldownMinutes
+	}
+	if a.PlacementHistoryTTLMinutes <= 0 {
+		a.PlacementHistoryTTLMinutes = DefaultPlacementHistoryTTLMinutes
+	}
+	if a.ScoreHistorySize <= 0 {
+		a.ScoreHistorySize = DefaultScoreHistorySize
+	}
+	a.FilterReadMode = readModeOrDefault("filterReadMode", a.FilterReadMode)
+	a.ScoreReadMode = readModeOrDefault("scoreReadMode", a.ScoreReadMode)
+	a.ReserveReadMode = readModeOrDefault("reserveReadMode", a.ReserveReadMode)
+	curve, err := score.ParseThermalCurve(a.ThermalDerateCurve)
+	if err != nil {
+		klog.Warningf("invalid thermalDerateCurve %q, not derating hot cards: %v", a.ThermalDerateCurve, err)
+	}
+	a.thermalCurve = curve
+	if a.MaxPodGpuMemory != "" && filter.ParseMemory(a.MaxPodGpuMemory) == 0 {
+		klog.Warningf("invalid maxPodGpuMemory %q, not capping GPU memory per pod", a.MaxPodGpuMemory)
+		a.MaxPodGpuMemory = ""
+	}
+	if a.MinScvAgentVersion != "" {
+		if _, err := version.ParseSemantic(a.MinScvAgentVersion); err != nil {
+			klog.Warningf("invalid minScvAgentVersion %q, not checking agent versions: %v", a.MinScvAgentVersion, err)
+			a.MinScvAgentVersion = ""
+		}
+	}
+	for name, severity := range a.PredicateSeverity {
+		switch severity {
+		case filter.SeverityReject, filter.SeverityWarn:
+		default:
+			klog.Warningf("invalid predicateSeverity %q for predicate %v, falling back to %q", severity, name, filter.SeverityReject)
+			a.PredicateSeverity[name] = filter.SeverityReject
+		}
+	}
+	switch a.OnScvClientFailure {
+	case ScvClientFailureFail, ScvClientFailurePassthrough, ScvClientFailureRetry:
+	default:
+		if a.OnScvClientFailure != "" {
+			klog.Warningf("invalid onScvClientFailure %q, falling back to %q", a.OnScvClientFailure, ScvClientFailureFail)
+		}
+		a.OnScvClientFailure = ScvClientFailureFail
+	}
+	if a.ScvGroupVersionKind != "" {
+		if _, err := ParseScvGroupVersionKind(a.ScvGroupVersionKind); err != nil {
+			klog.Warningf("invalid scvGroupVersionKind %q, using the upstream SCV kind: %v", a.ScvGroupVersionKind, err)
+			a.ScvGroupVersionKind = ""
+		}
+	}
+	switch a.SysmemPerGpuMode {
+	case filter.SysmemHard, filter.SysmemSoft:
+	default:
+		if a.SysmemPerGpuMode != "" {
+			klog.Warningf("invalid sysmemPerGpuMode %q, falling back to %q", a.SysmemPerGpuMode, filter.SysmemHard)
+		}
+		a.SysmemPerGpuMode = filter.SysmemHard
+	}
+	switch a.EqualScoreFallback {
+	case score.FallbackNone, score.FallbackLeastRecentlyUsed, score.FallbackRoundRobin, score.FallbackRandom:
+	default:
+		klog.Warningf("invalid equalScoreFallback %q, leaving ties to the scheduler", a.EqualScoreFallback)
+		a.EqualScoreFallback = score.FallbackNone
+	}
+	switch a.InitContainerGpuPolicy {
+	case filter.InitContainerPeak, filter.InitContainerSum:
+	default:
+		if a.InitContainerGpuPolicy != "" {
+			klog.Warningf("invalid initContainerGpuPolicy %q, falling back to %q", a.InitContainerGpuPolicy, filter.InitContainerPeak)
+		}
+		a.InitContainerGpuPolicy = filter.InitContainerPeak
+	}
+	switch a.RespectDrain {
+	case "", filter.RespectDrainPenalize, filter.RespectDrainExclude:
+	default:
+		klog.Warningf("invalid respectDrain %q, not avoiding drained nodes", a.RespectDrain)
+		a.RespectDrain = ""
+	}
+	switch a.MissingDataPolicy {
+	case score.MissingDataPenalize, score.MissingDataNeutral, score.MissingDataExcludeTerm:
+	default:
+		if a.MissingDataPolicy != "" {
+			klog.Warningf("invalid missingDataPolicy %q, falling back to %q", a.MissingDataPolicy, score.MissingDataPenalize)
+		}
+		a.MissingDataPolicy = score.MissingDataPenalize
+	}
+	switch a.ScoringStrategy {
+	case score.StrategyDefault, score.StrategyBinpack2D:
+	default:
+		klog.Warningf("invalid scoringStrategy %q, falling back to the default strategy", a.ScoringStrategy)
+		a.ScoringStrategy = score.StrategyDefault
+	}
+	switch a.CardScoreAggregation {
+	case score.AggregationMax, score.AggregationMean, score.AggregationTopK:
+	default:
+		if a.CardScoreAggregation != "" {
+			klog.Warningf("invalid cardScoreAggregation %q, falling back to %q", a.CardScoreAggregation, score.AggregationMax)
+		}
+		a.CardScoreAggregation = score.AggregationMax
+	}
+}
+
+func readModeOrDefault(field, mode string) string {
+	switch scvcache.ReadMode(mode) {
+	case scvcache.ReadLive, scvcache.ReadCache:
+		return mode
+	case "":
+	default:
+		klog.Warningf("invalid %v %q, falling back to %q", field, mode, scvcache.ReadLive)
+	}
+	return string(scvcache.ReadLive)
+}
+
+func (a *Args) Predicates(l *ledger.Ledger) []filter.Predicate {
+	predicates := append(filter.DefaultPredicates(), filter.NewScratchPredicate(a.RequireScratchInfo))
+	if a.StrictGpuNodeIsolation {
+		predicates = append(predicates, filter.NewGpuIsolationPredicate())
+	}
+	if a.RespectScaleDown {
+		predicates = append(predicates, filter.NewScaleDownPredicate())
+	}
+	if a.EnforceCpuRatio {
+		predicates = append(predicates, filter.NewCpuRatioPredicate())
+	}
+	if a.RespectDrain == filter.RespectDrainExclude {
+		predicates = append(predicates, filter.NewDrainPredicate())
+	}
+	if a.SysmemPerGpuMode == filter.SysmemHard {
+		predicates = append(predicates, filter.NewSysmemPredicate())
+	}
+	if a.DriftToleranceMB > 0 {
+		predicates = append(predicates, filter.NewDriftPredicate(l, a.DriftToleranceMB))
+	}
+	if a.SharedCardMemoryGapMB > 0 {
+		predicates = append(predicates, filter.NewSharedCardGapPredicate(l, a.SharedCardMemoryGapMB))
+	}
+	if a.MinScvAgentVersion != "" {
+		predicates = append(predicates, filter.NewAgentVersionPredicate(version.MustParseSemantic(a.MinScvAgentVersion)))
+	}
+	for i, p := range predicates {
+		if a.PredicateSeverity[p.Name()] == filter.SeverityWarn {
+			predicates[i] = filter.WithWarning(p, warnPredicate)
+		}
+	}
+	return predicates
+}
+
+func (a *Args) ScoreOptions() score.Options {
+	return score.Options{
+		HomogeneityWeight:          a.HomogeneityWeight,
+		CardAggregation:            a.CardScoreAggregation,
+		CardTopK:                   a.CardScoreTopK,
+		DeadlineUrgencyWeight:      a.DeadlineUrgencyWeight,
+		DeadlineHorizon:            time.Duration(a.DeadlineHorizonMinutes) * time.Minute,
+		PowerStateWeight:           a.PowerStateWeight,
+		PowerSaving:                a.PowerSaving,
+		ArchMatchWeight:            a.ArchMatchWeight,
+		BigNodeReservationWeight:   a.BigNodeReservationWeight,
+		NeutralScore:               a.NeutralScore,
+		Strategy:                   a.ScoringStrategy,
+		ResidentModelWeight:        a.ResidentModelWeight,
+		CheckpointAffinityWeight:   a.CheckpointAffinityWeight,
+		CpuRatioWeight:             a.CpuRatioWeight,
+		BandwidthContentionWeight:  a.BandwidthContentionWeight,
+		AttestationWeight:          a.AttestationWeight,
+		ThermalCurve:               a.thermalCurve,
+		RecentlyFreedWeight:        a.RecentlyFreedWeight,
+		PlacementHistoryWeight:     a.PlacementHistoryWeight,
+		PcieSwitchWeight:           a.PcieSwitchWeight,
+		IdleNodePenaltyWeight:      a.idleNodePenaltyWeight(),
+		ModeMatchWeight:            a.ModeMatchWeight,
+		SidecarWarmWeight:          a.SidecarWarmWeight,
+		VendorBalanceWeight:        a.VendorBalanceWeight,
+		MissingDataPolicy:          a.MissingDataPolicy,
+		ClockUniformityWeight:      a.ClockUniformityWeight,
+		RespectDrain:               a.RespectDrain,
+		KernelErrorWeight:          a.KernelErrorWeight,
+		MaxBonusContribution:       float64(a.MaxBonusContribution),
+		MigRepartitionWeight:       a.MigRepartitionWeight,
+		InteractiveIsolationWeight: a.InteractiveIsolationWeight,
+		FirstConsumerPenalty:       a.FirstConsumerPenalty,
+		ClockLockWeight:            a.ClockLockWeight,
+		ExternalScoreWeight:        a.ExternalScoreWeight,
+		MpsReuseWeight:             a.MpsReuseWeight,
+		SysmemPerGpuMode:           a.SysmemPerGpuMode,
+	}
+}
+
+// DefaultScoreOptions are the score options of an empty configuration, the
+// ones the scoring self-test is calibrated against.
+func DefaultScoreOptions() score.Options {
+	a := &Args{}
+	a.SetDefaults()
+	return a.ScoreOptions()
+}
+
+// idleNodePenaltyWeight only applies when consolidation is preferred.
+func (a *Args) idleNodePenaltyWeight() uint64 {
+	if !a.PreferConsolidation {
+		return 0
+	}
+	return a.IdleNodePenaltyWeight
+}
+
+func warnPredicate(predicate, node, reason string) {
+	klog.Warningf("predicate %v failed on node %v, keeping it because of warn severity: %v", predicate, node, reason)
+	metrics.PredicateWarnings.WithLabelValues(predicate).Inc()
+}
+
+func ApplyClientLimits(config *rest.Config, args *Args) {
+	config.QPS = args.ScvClientQPS
+	config.Burst = args.ScvClientBurst
+}
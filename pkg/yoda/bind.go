@@ -0,0 +1,240 @@
+package yoda
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/ledger"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/score"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/scvcache"
+)
+
+const CardsStateKey = "Cards"
+
+type CardsState struct {
+	Reservation ledger.Reservation
+}
+
+func (c *CardsState) Clone() framework.StateData {
+	r := c.Reservation
+	r.Cards = append([]int(nil), c.Reservation.Cards...)
+	return &CardsState{Reservation: r}
+}
+
+func (y *Yoda) Reserve(ctx context.Context, state *framework.CycleState, p *v1.Pod, nodeName string) *framework.Status {
+	if filter.PodRequestsNoGpu(p) || y.passthrough() {
+		return framework.NewStatus(framework.Success, "")
+	}
+	currentScv := &scv.Scv{}
+	if err := y.scvs.Get(ctx, nodeName, scvcache.ReadMode(y.args.ReserveReadMode), currentScv); err != nil {
+		klog.Errorf("Get SCV Error: %v", err)
+		return framework.NewStatus(framework.Error, fmt.Sprintf("Reserve Node Error: %v", err))
+	}
+	if nodeInfo, err := y.handle.SnapshotSharedLister().NodeInfos().Get(nodeName); err == nil {
+		filter.ApplyEccReserved(currentScv, nodeInfo.Node())
+	}
+	reserved := y.reservedMemory(p, currentScv)
+	cards, memory, ok := filter.SelectCards(p, currentScv, reserved)
+	if !ok {
+		return framework.NewStatus(framework.Unschedulable, "Node:"+nodeName+" no longer fits the pod")
+	}
+	if candidate, ok := readCandidateCards(state, nodeName); ok && len(candidate) == len(cards) && cardsFit(p, currentScv, candidate, memory, reserved) {
+		cards = candidate
+	}
+	r := ledger.Reservation{
+		Node:           nodeName,
+		Cards:          cards,
+		Memory:         memory,
+		Compute:        filter.PodCompute(p),
+		BandwidthHeavy: filter.PodAnnotationIsTrue(p, filter.BandwidthHeavyAnnotation),
+		Class:          filter.PodWorkloadClass(p),
+		Mps:            score.PodNeedsMps(p),
+		Reserved:       y.clock.Now(),
+	}
+	state.Lock()
+	state.Write(CardsStateKey, &CardsState{Reservation: r})
+	state.Unlock()
+	y.ledger.Reserve(p.GetUID(), r)
+	y.ties.Chosen(nodeName, y.clock.Now())
+	klog.V(3).Infof("reserve cards %v on node %v for pod %v", cards, nodeName, p.Name)
+	return framework.NewStatus(framework.Success, "")
+}
+
+// reservedMemory is the memory cards on the node cannot give the pod: what other
+// pods reserved, plus drifted cards and shared card gaps when configured.
+func (y *Yoda) reservedMemory(p *v1.Pod, s *scv.Scv) map[int]uint64 {
+	reserved := y.ledger.ReservedCardMemory(s.GetName(), p.GetUID())
+	if y.args.DriftToleranceMB > 0 {
+		reserved = filter.BlockCards(s, reserved, filter.DriftedCards(s, reserved, y.args.DriftToleranceMB))
+	}
+	if y.args.SharedCardMemoryGapMB > 0 {
+		reserved = filter.SharedCardGap(s, reserved, y.args.SharedCardMemoryGapMB)
+	}
+	return reserved
+}
+
+func (y *Yoda) PreBind(ctx context.Context, state *framework.CycleState, p *v1.Pod, nodeName string) *framework.Status {
+	if filter.PodRequestsNoGpu(p) || y.passthrough() {
+		return framework.NewStatus(framework.Success, "")
+	}
+	state.RLock()
+	d, err := state.Read(CardsStateKey)
+	state.RUnlock()
+	if err != nil {
+		return framework.NewStatus(framework.Error, fmt.Sprintf("PreBind Error: %v", err))
+	}
+	c, ok := d.(*CardsState)
+	if !ok {
+		return framework.NewStatus(framework.Error, "The Type is not CardsState ")
+	}
+	r, status := y.writeReservation(ctx, p, nodeName, c.Reservation)
+	if !status.IsSuccess() {
+		return status
+	}
+	state.Lock()
+	state.Write(CardsStateKey, &CardsState{Reservation: r})
+	state.Unlock()
+	y.ledger.Reserve(p.GetUID(), r)
+	detail, _ := chosenScoreDetail(state, nodeName)
+	if err := y.annotatePod(p, r.Cards, detail); err != nil {
+		return framework.NewStatus(framework.Error, fmt.Sprintf("Annotate Pod Error: %v", err))
+	}
+	return framework.NewStatus(framework.Success, "")
+}
+
+// writeReservation stores the reservation on the node's SCV. When the write
+// conflicts, the decision is re-evaluated against the fresh SCV instead of
+// retrying the stale one, up to MaxDecisionRetries times.
+func (y *Yoda) writeReservation(ctx context.Context, p *v1.Pod, nodeName string, r ledger.Reservation) (ledger.Reservation, *framework.Status) {
+	for attempt := 0; ; attempt++ {
+		s := &scv.Scv{}
+		if err := y.scvs.Client().Get(ctx, types.NamespacedName{Name: nodeName}, s); err != nil {
+			return r, framework.NewStatus(framework.Error, fmt.Sprintf("Get SCV Error: %v", err))
+		}
+		fresh, ok := revalidate(p, s, r)
+		if !ok {
+			return r, framework.NewStatus(framework.Unschedulable, "Node:"+nodeName+" no longer fits the pod after its SCV changed")
+		}
+		if err := ledger.SetScvReservation(s, p.GetUID(), fresh); err != nil {
+			return r, framework.NewStatus(framework.Error, fmt.Sprintf("Write SCV Reservation Error: %v", err))
+		}
+		err := y.scvs.Client().Update(ctx, s)
+		y.scvs.Invalidate(nodeName)
+		if err == nil {
+			return fresh, framework.NewStatus(framework.Success, "")
+		}
+		if !apierrors.IsConflict(err) || attempt >= y.args.MaxDecisionRetries {
+			return r, framework.NewStatus(framework.Error, fmt.Sprintf("Write SCV Reservation Error: %v", err))
+		}
+		klog.V(3).Infof("SCV of node %v changed while reserving pod %v, re-evaluating", nodeName, p.Name)
+	}
+}
+
+// revalidate keeps the reserved cards if they still fit given the SCV's current
+// data and the other reservations recorded on it, and otherwise picks again on the node.
+func revalidate(p *v1.Pod, s *scv.Scv, r ledger.Reservation) (ledger.Reservation, bool) {
+	reserved := map[int]uint64{}
+	for uid, other := range ledger.ScvReservations(s, r.Reserved) {
+		if uid == p.GetUID() {
+			continue
+		}
+		for _, card := range other.Cards {
+			reserved[card] += other.Memory
+		}
+	}
+	fits := true
+	for _, card := range r.Cards {
+		if card >= len(s.Status.CardList) || !filter.CardFitsMemory(r.Memory+reserved[card], s.Status.CardList[card]) {
+			fits = false
+			break
+		}
+	}
+	if fits {
+		return r, true
+	}
+	cards, memory, ok := filter.SelectCards(p, s, reserved)
+	if !ok {
+		return r, false
+	}
+	r.Cards, r.Memory = cards, memory
+	return r, true
+}
+
+func (y *Yoda) Unreserve(ctx context.Context, state *framework.CycleState, p *v1.Pod, nodeName string) {
+	if filter.PodRequestsNoGpu(p) {
+		return
+	}
+	y.ledger.Release(p.GetUID())
+	y.removeScvReservation(ctx, nodeName, p.GetUID())
+}
+
+// removeScvReservation drops the pod's entry from the node's SCV once the device
+// plugin no longer needs it.
+func (y *Yoda) removeScvReservation(ctx context.Context, nodeName string, uid types.UID) {
+	if y.passthrough() {
+		return
+	}
+	err := y.updateScv(ctx, nodeName, func(s *scv.Scv) (bool, error) {
+		return ledger.RemoveScvReservation(s, uid)
+	})
+	if err != nil {
+		klog.Errorf("Remove SCV Reservation Error: %v", err)
+	}
+}
+
+// annotatePod records the assigned cards on the pod, and for elastic pods how
+// many were granted so the job can size its parallelism. A non-empty detail is
+// the chosen node's score breakdown for pods that asked to debug their placement.
+func (y *Yoda) annotatePod(p *v1.Pod, cards []int, detail string) error {
+	ids := make([]string, len(cards))
+	for i, card := range cards {
+		ids[i] = strconv.Itoa(card)
+	}
+	annotations := map[string]string{filter.CardsAnnotation: strings.Join(ids, ",")}
+	if _, _, ok := filter.PodElasticRange(p); ok {
+		annotations[filter.ElasticGrantedAnnotation] = strconv.Itoa(len(cards))
+	}
+	if detail != "" {
+		annotations[ChosenScoreDetailAnnotation] = detail
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = y.handle.ClientSet().CoreV1().Pods(p.Namespace).Patch(p.Name, types.MergePatchType, patch)
+	return err
+}
+
+// updateScv applies mutate to a fresh copy of the node's SCV and writes it back,
+// retrying when the object changed underneath us.
+func (y *Yoda) updateScv(ctx context.Context, nodeName string, mutate func(s *scv.Scv) (bool, error)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		s := &scv.Scv{}
+		if err := y.scvs.Client().Get(ctx, types.NamespacedName{Name: nodeName}, s); err != nil {
+			return err
+		}
+		changed, err := mutate(s)
+		if err != nil || !changed {
+			return err
+		}
+		defer y.scvs.Invalidate(nodeName)
+		return y.scvs.Client().Update(ctx, s)
+	})
+}
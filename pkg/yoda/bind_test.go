@@ -0,0 +1,417 @@
+package yoda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	schedulerlisters "k8s.io/kubernetes/pkg/scheduler/listers"
+	nodeinfosnapshot "k8s.io/kubernetes/pkg/scheduler/nodeinfo/snapshot"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/ledger"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/score"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/scvcache"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/stats"
+)
+
+type fakeHandle struct {
+	framework.FrameworkHandle
+	clientSet clientset.Interface
+	snapshot  *nodeinfosnapshot.Snapshot
+	informers informers.SharedInformerFactory
+}
+
+func (h *fakeHandle) ClientSet() clientset.Interface {
+	return h.clientSet
+}
+
+func (h *fakeHandle) SnapshotSharedLister() schedulerlisters.SharedLister {
+	return h.snapshot
+}
+
+func (h *fakeHandle) SharedInformerFactory() informers.SharedInformerFactory {
+	return h.informers
+}
+
+func testScvClient(t *testing.T, objs ...runtime.Object) client.Client {
+	s := runtime.NewScheme()
+	if err := scv.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	return fakeclient.NewFakeClientWithScheme(s, objs...)
+}
+
+// newTestYoda builds the plugin with default args against fake clients holding
+// the given pod, nodes and SCVs.
+func newTestYoda(t *testing.T, pod *v1.Pod, nodes []*v1.Node, scvs ...runtime.Object) *Yoda {
+	args := &Args{}
+	args.SetDefaults()
+	c := clock.RealClock{}
+	objs := []runtime.Object{pod}
+	for _, node := range nodes {
+		objs = append(objs, node)
+	}
+	cs := fake.NewSimpleClientset(objs...)
+	l := ledger.New()
+	return &Yoda{
+		args: args,
+		handle: &fakeHandle{
+			clientSet: cs,
+			snapshot:  nodeinfosnapshot.NewSnapshot(nodeinfosnapshot.CreateNodeInfoMap(nil, nodes)),
+			informers: informers.NewSharedInformerFactory(cs, 0),
+		},
+		scvs:       scvcache.New(testScvClient(t, scvs...), c, 0),
+		clock:      c,
+		predicates: args.Predicates(l),
+		rejections: stats.NewWindow(c, time.Hour, time.Minute),
+		ledger:     l,
+		history:    stats.NewPlacementHistory(c, time.Hour),
+		ties:       score.NewTieBreaker(args.EqualScoreFallback, args.EqualScoreFallbackSeed),
+	}
+}
+
+func testNode(name string) *v1.Node {
+	return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func testScv(name string, cards ...scv.Card) *scv.Scv {
+	s := &scv.Scv{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	for _, card := range cards {
+		s.Status.CardList = append(s.Status.CardList, card)
+		s.Status.TotalMemorySum += card.TotalMemory
+		s.Status.FreeMemorySum += card.FreeMemory
+		s.Status.CardNumber++
+	}
+	return s
+}
+
+func testCard(free, total uint64) scv.Card {
+	return scv.Card{Health: "Healthy", TotalMemory: total, FreeMemory: free, Clock: 1500}
+}
+
+func TestReservationHandoff(t *testing.T) {
+	tests := []struct {
+		name string
+		// fillBeforePreBind takes the card's memory between Reserve and PreBind.
+		fillBeforePreBind bool
+		// bindFails makes the framework unreserve after a successful PreBind.
+		bindFails       bool
+		wantPreBind     bool
+		wantReservation bool
+	}{
+		{name: "recorded on the SCV after PreBind", wantPreBind: true, wantReservation: true},
+		{name: "removed when binding fails", bindFails: true, wantPreBind: true, wantReservation: false},
+		{name: "not left behind when PreBind fails", fillBeforePreBind: true, wantPreBind: false, wantReservation: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "uid", Labels: map[string]string{"scv/memory": "4000"}}}
+			y := newTestYoda(t, pod, []*v1.Node{testNode("node")}, testScv("node", testCard(8000, 12000)))
+			state := framework.NewCycleState()
+			if status := y.Reserve(ctx, state, pod, "node"); !status.IsSuccess() {
+				t.Fatalf("Reserve() = %v", status.Message())
+			}
+			if tt.fillBeforePreBind {
+				s := &scv.Scv{}
+				if err := y.scvs.Client().Get(ctx, types.NamespacedName{Name: "node"}, s); err != nil {
+					t.Fatal(err)
+				}
+				s.Status.CardList[0].FreeMemory = 1000
+				if err := y.scvs.Client().Update(ctx, s); err != nil {
+					t.Fatal(err)
+				}
+			}
+			status := y.PreBind(ctx, state, pod, "node")
+			if status.IsSuccess() != tt.wantPreBind {
+				t.Fatalf("PreBind() = %v, want success %v", status.Message(), tt.wantPreBind)
+			}
+			if !status.IsSuccess() || tt.bindFails {
+				y.Unreserve(ctx, state, pod, "node")
+			}
+			s := &scv.Scv{}
+			if err := y.scvs.Client().Get(ctx, types.NamespacedName{Name: "node"}, s); err != nil {
+				t.Fatal(err)
+			}
+			r, ok := ledger.ScvReservations(s, y.clock.Now())[pod.GetUID()]
+			if ok != tt.wantReservation {
+				t.Fatalf("SCV reservation present = %v, want %v", ok, tt.wantReservation)
+			}
+			if ok && (len(r.Cards) != 1 || r.Cards[0] != 0 || r.Memory != 4000) {
+				t.Errorf("SCV reservation = %+v, want card 0 with 4000MB", r)
+			}
+		})
+	}
+}
+
+// conflictingClient fails the first conflicts SCV updates with a conflict,
+// calling change first to stand in for the concurrent writer.
+type conflictingClient struct {
+	client.Client
+	conflicts int
+	updates   int
+	change    func(ctx context.Context, c client.Client) error
+}
+
+func (c *conflictingClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	c.updates++
+	if c.updates > c.conflicts {
+		return c.Client.Update(ctx, obj, opts...)
+	}
+	if err := c.change(ctx, c.Client); err != nil {
+		return err
+	}
+	return apierrors.NewConflict(scv.GroupVersion.WithResource("scvs").GroupResource(), "node", errors.New("the object has been modified"))
+}
+
+func TestPreBindReevaluatesOnConflict(t *testing.T) {
+	// fill takes the memory of the given cards, as another scheduler placing pods would.
+	fill := func(cards ...int) func(ctx context.Context, c client.Client) error {
+		return func(ctx context.Context, c client.Client) error {
+			s := &scv.Scv{}
+			if err := c.Get(ctx, types.NamespacedName{Name: "node"}, s); err != nil {
+				return err
+			}
+			for _, card := range cards {
+				s.Status.CardList[card].FreeMemory = 1000
+			}
+			return c.Update(ctx, s)
+		}
+	}
+	tests := []struct {
+		name        string
+		conflicts   int
+		retries     int
+		change      func(ctx context.Context, c client.Client) error
+		wantPreBind bool
+		wantCard    int
+		wantUpdates int
+	}{
+		{name: "no conflict keeps the first decision", wantPreBind: true, wantCard: 0, wantUpdates: 1},
+		{name: "re-evaluated onto the card left free", conflicts: 1, retries: 3, change: fill(0), wantPreBind: true, wantCard: 1, wantUpdates: 2},
+		{name: "unschedulable when the fresh SCV no longer fits", conflicts: 1, retries: 3, change: fill(0, 1), wantPreBind: false, wantUpdates: 1},
+		{name: "gives up after the configured retries", conflicts: 3, retries: 2, change: fill(), wantPreBind: false, wantUpdates: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "uid", Labels: map[string]string{"scv/memory": "4000"}}}
+			y := newTestYoda(t, pod, []*v1.Node{testNode("node")})
+			cl := &conflictingClient{
+				Client:    testScvClient(t, testScv("node", testCard(10000, 12000), testCard(6000, 12000))),
+				conflicts: tt.conflicts,
+				change:    tt.change,
+			}
+			y.scvs = scvcache.New(cl, y.clock, 0)
+			y.args.MaxDecisionRetries = tt.retries
+			state := framework.NewCycleState()
+			if status := y.Reserve(ctx, state, pod, "node"); !status.IsSuccess() {
+				t.Fatalf("Reserve() = %v", status.Message())
+			}
+			status := y.PreBind(ctx, state, pod, "node")
+			if status.IsSuccess() != tt.wantPreBind {
+				t.Fatalf("PreBind() = %v, want success %v", status.Message(), tt.wantPreBind)
+			}
+			if cl.updates != tt.wantUpdates {
+				t.Errorf("SCV updates = %v, want %v", cl.updates, tt.wantUpdates)
+			}
+			if !tt.wantPreBind {
+				return
+			}
+			s := &scv.Scv{}
+			if err := cl.Get(ctx, types.NamespacedName{Name: "node"}, s); err != nil {
+				t.Fatal(err)
+			}
+			r, ok := ledger.ScvReservations(s, y.clock.Now())[pod.GetUID()]
+			if !ok || len(r.Cards) != 1 || r.Cards[0] != tt.wantCard {
+				t.Errorf("SCV reservation = %+v, %v, want card %v", r, ok, tt.wantCard)
+			}
+			if got, _ := y.ledger.Get(pod.GetUID()); len(got.Cards) != 1 || got.Cards[0] != tt.wantCard {
+				t.Errorf("ledger reservation = %+v, want card %v", got, tt.wantCard)
+			}
+		})
+	}
+}
+
+func TestElasticGrant(t *testing.T) {
+	tests := []struct {
+		name        string
+		free        int
+		wantFits    bool
+		wantGranted string
+	}{
+		{name: "below the minimum", free: 1, wantFits: false},
+		{name: "exactly the minimum", free: 2, wantFits: true, wantGranted: "2"},
+		{name: "between the minimum and maximum", free: 3, wantFits: true, wantGranted: "3"},
+		{name: "capped at the maximum", free: 6, wantFits: true, wantGranted: "4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name: "pod", Namespace: "default", UID: "uid",
+				Labels:      map[string]string{"scv/memory": "4000"},
+				Annotations: map[string]string{filter.ElasticMinAnnotation: "2", filter.ElasticMaxAnnotation: "4"},
+			}}
+			var cards []scv.Card
+			for i := 0; i < tt.free; i++ {
+				cards = append(cards, testCard(8000, 8000))
+			}
+			y := newTestYoda(t, pod, []*v1.Node{testNode("node")}, testScv("node", cards...))
+			state := framework.NewCycleState()
+			if status := y.PreFilter(ctx, state, pod); !status.IsSuccess() {
+				t.Fatalf("PreFilter() = %v", status.Message())
+			}
+			nodeInfo, err := y.handle.SnapshotSharedLister().NodeInfos().Get("node")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status := y.Filter(ctx, state, pod, nodeInfo); status.IsSuccess() != tt.wantFits {
+				t.Fatalf("Filter() = %v, want success %v", status.Message(), tt.wantFits)
+			}
+			if !tt.wantFits {
+				return
+			}
+			if status := y.Reserve(ctx, state, pod, "node"); !status.IsSuccess() {
+				t.Fatalf("Reserve() = %v", status.Message())
+			}
+			if status := y.PreBind(ctx, state, pod, "node"); !status.IsSuccess() {
+				t.Fatalf("PreBind() = %v", status.Message())
+			}
+			got, err := y.handle.ClientSet().CoreV1().Pods("default").Get("pod", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if granted := got.Annotations[filter.ElasticGrantedAnnotation]; granted != tt.wantGranted {
+				t.Errorf("granted %v cards, want %v", granted, tt.wantGranted)
+			}
+		})
+	}
+}
+
+func TestDriftRespected(t *testing.T) {
+	// Card 0 reports 2000MB in use that no reservation accounts for.
+	drifted := testCard(10000, 12000)
+	tests := []struct {
+		name      string
+		tolerance uint64
+		cards     []scv.Card
+		wantFits  bool
+		wantCard  int
+	}{
+		{name: "drift ignored without a tolerance", cards: []scv.Card{drifted, testCard(8000, 8000)}, wantFits: true, wantCard: 0},
+		{name: "drifted card avoided", tolerance: 1000, cards: []scv.Card{drifted, testCard(8000, 8000)}, wantFits: true, wantCard: 1},
+		{name: "drift within the tolerance", tolerance: 4000, cards: []scv.Card{drifted, testCard(8000, 8000)}, wantFits: true, wantCard: 0},
+		{name: "node with only drifted cards filtered", tolerance: 1000, cards: []scv.Card{drifted}, wantFits: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "uid", Labels: map[string]string{"scv/memory": "4000"}}}
+			y := newTestYoda(t, pod, []*v1.Node{testNode("node")}, testScv("node", tt.cards...))
+			y.args.DriftToleranceMB = tt.tolerance
+			y.predicates = y.args.Predicates(y.ledger)
+
+			state := framework.NewCycleState()
+			nodeInfo, err := y.handle.SnapshotSharedLister().NodeInfos().Get("node")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status := y.Filter(ctx, state, pod, nodeInfo); status.IsSuccess() != tt.wantFits {
+				t.Fatalf("Filter() = %v, want success %v", status.Message(), tt.wantFits)
+			}
+			if !tt.wantFits {
+				return
+			}
+			if status := y.Reserve(ctx, state, pod, "node"); !status.IsSuccess() {
+				t.Fatalf("Reserve() = %v", status.Message())
+			}
+			if r, _ := y.ledger.Get(pod.GetUID()); len(r.Cards) != 1 || r.Cards[0] != tt.wantCard {
+				t.Errorf("reserved cards %v, want card %v", r.Cards, tt.wantCard)
+			}
+		})
+	}
+}
+
+func TestChosenScoreDetail(t *testing.T) {
+	for _, debug := range []bool{false, true} {
+		t.Run(fmt.Sprintf("debug=%v", debug), func(t *testing.T) {
+			ctx := context.Background()
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "uid", Labels: map[string]string{"scv/memory": "4000"}}}
+			if debug {
+				pod.Annotations = map[string]string{DebugAnnotation: "true"}
+			}
+			nodes := []*v1.Node{testNode("node")}
+			y := newTestYoda(t, pod, nodes, testScv("node", testCard(8000, 12000), testCard(4000, 12000)))
+			state := framework.NewCycleState()
+			if status := y.PreFilter(ctx, state, pod); !status.IsSuccess() {
+				t.Fatalf("PreFilter() = %v", status.Message())
+			}
+			if status := y.PostFilter(ctx, state, pod, nodes, nil); !status.IsSuccess() {
+				t.Fatalf("PostFilter() = %v", status.Message())
+			}
+			nodeScore, status := y.Score(ctx, state, pod, "node")
+			if !status.IsSuccess() {
+				t.Fatalf("Score() = %v", status.Message())
+			}
+			if status := y.Reserve(ctx, state, pod, "node"); !status.IsSuccess() {
+				t.Fatalf("Reserve() = %v", status.Message())
+			}
+			if status := y.PreBind(ctx, state, pod, "node"); !status.IsSuccess() {
+				t.Fatalf("PreBind() = %v", status.Message())
+			}
+			got, err := y.handle.ClientSet().CoreV1().Pods("default").Get("pod", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			value, ok := got.Annotations[ChosenScoreDetailAnnotation]
+			if ok != debug {
+				t.Fatalf("score detail annotated = %v, want %v", ok, debug)
+			}
+			if !debug {
+				return
+			}
+			if len(value) > maxScoreDetailBytes {
+				t.Errorf("score detail is %v bytes, want at most %v", len(value), maxScoreDetailBytes)
+			}
+			detail := scoreDetail{}
+			if err := json.Unmarshal([]byte(value), &detail); err != nil {
+				t.Fatal(err)
+			}
+			if detail.Node != "node" || score.ToNodeScore(detail.Total) != nodeScore {
+				t.Errorf("score detail is for %v totalling %v, want node scoring %v", detail.Node, detail.Total, nodeScore)
+			}
+			if cards := got.Annotations[filter.CardsAnnotation]; len(detail.Cards) != 1 || strconv.Itoa(detail.Cards[0]) != cards {
+				t.Errorf("score detail cards = %v, want the assigned cards %v", detail.Cards, cards)
+			}
+			if sum := detail.Terms.Total(); math.Abs(sum-detail.Total) > 1e-6 {
+				t.Errorf("score detail terms sum to %v, want the total %v", sum, detail.Total)
+			}
+			for _, term := range detail.Terms {
+				if term.Score == 0 {
+					t.Errorf("score detail includes zero term %v", term.Name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package yoda
+
+import (
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+const CandidateCardsStateKey = "CandidateCards"
+
+// candidateCards holds, per scored node, the cards the pod would be given there.
+type candidateCards struct {
+	mu    sync.Mutex
+	nodes map[string][]int
+}
+
+func (c *candidateCards) Clone() framework.StateData {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	nodes := map[string][]int{}
+	for k, v := range c.nodes {
+		nodes[k] = append([]int(nil), v...)
+	}
+	return &candidateCards{nodes: nodes}
+}
+
+func recordCandidateCards(state *framework.CycleState, nodeName string, cards []int) {
+	state.Lock()
+	d, err := state.Read(CandidateCardsStateKey)
+	candidates, ok := d.(*candidateCards)
+	if err != nil || !ok {
+		candidates = &candidateCards{nodes: map[string][]int{}}
+		state.Write(CandidateCardsStateKey, candidates)
+	}
+	state.Unlock()
+	candidates.mu.Lock()
+	candidates.nodes[nodeName] = append([]int(nil), cards...)
+	candidates.mu.Unlock()
+}
+
+func readCandidateCards(state *framework.CycleState, nodeName string) ([]int, bool) {
+	state.RLock()
+	d, err := state.Read(CandidateCardsStateKey)
+	state.RUnlock()
+	candidates, ok := d.(*candidateCards)
+	if err != nil || !ok {
+		return nil, false
+	}
+	candidates.mu.Lock()
+	defer candidates.mu.Unlock()
+	cards, ok := candidates.nodes[nodeName]
+	return append([]int(nil), cards...), ok
+}
+
+// cardsFit reports whether each of the cards can still take the pod's memory on top of reserved.
+func cardsFit(p *v1.Pod, s *scv.Scv, cards []int, memory uint64, reserved map[int]uint64) bool {
+	_, clock := filter.PodFitsClock(uint(len(cards)), p, s)
+	for _, card := range cards {
+		if card < 0 || card >= len(s.Status.CardList) || !filter.CardFits(memory+reserved[card], clock, s.Status.CardList[card]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package yoda
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/ledger"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/scvcache"
+)
+
+func TestRecordedCandidateCards(t *testing.T) {
+	ctx := context.Background()
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod", Labels: map[string]string{"scv/memory": "3000"}}}
+	nodes := []*v1.Node{testNode("node")}
+	y := newTestYoda(t, pod, nodes, testScv("node", testCard(4000, 8000), testCard(8000, 8000), testCard(6000, 8000)))
+	// The fullest free card is promised to another pod, so the scorer settles on card 2.
+	y.ledger.Reserve("other", ledger.Reservation{Node: "node", Cards: []int{1}, Memory: 6000})
+	state := framework.NewCycleState()
+	if status := y.PreFilter(ctx, state, pod); !status.IsSuccess() {
+		t.Fatalf("PreFilter() = %v", status.Message())
+	}
+	if status := y.PostFilter(ctx, state, pod, nodes, nil); !status.IsSuccess() {
+		t.Fatalf("PostFilter() = %v", status.Message())
+	}
+	if _, status := y.Score(ctx, state, pod, "node"); !status.IsSuccess() {
+		t.Fatalf("Score() = %v", status.Message())
+	}
+	s := &scv.Scv{}
+	if err := y.scvs.Get(ctx, "node", scvcache.ReadLive, s); err != nil {
+		t.Fatal(err)
+	}
+	evaluated, _, _ := filter.SelectCards(pod, s, y.reservedMemory(pod, s))
+	recorded, ok := readCandidateCards(state, "node")
+	if !ok || !reflect.DeepEqual(recorded, evaluated) || !reflect.DeepEqual(recorded, []int{2}) {
+		t.Fatalf("recorded cards = %v %v, want the evaluated cards %v", recorded, ok, evaluated)
+	}
+
+	// Card 0 frees up before Reserve; the pod still gets the card it was scored on.
+	s.Status.CardList[0].FreeMemory = 8000
+	if err := y.scvs.Client().Update(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	if status := y.Reserve(ctx, state, pod, "node"); !status.IsSuccess() {
+		t.Fatalf("Reserve() = %v", status.Message())
+	}
+	if r, ok := y.ledger.Get(types.UID("pod")); !ok || !reflect.DeepEqual(r.Cards, recorded) {
+		t.Errorf("reserved cards = %v %v, want the recorded cards %v", r.Cards, ok, recorded)
+	}
+}
@@ -0,0 +1,56 @@
+package collection
+
+import (
+	v1 "k8s.io/api/core/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/ledger"
+)
+
+const ConsolidationStateKey = "Consolidation"
+
+// Consolidation records whether a partially used feasible node can take the pod.
+type Consolidation struct {
+	Absorbable bool
+}
+
+func (c *Consolidation) Clone() framework.StateData {
+	return &Consolidation{Absorbable: c.Absorbable}
+}
+
+// CollectConsolidation checks whether any feasible node that is already in use
+// can absorb the pod, so idle nodes need not be woken.
+func CollectConsolidation(state *framework.CycleState, pod *v1.Pod, nodes []*v1.Node, scvList scv.ScvList, l *ledger.Ledger) {
+	feasible := map[string]bool{}
+	for _, node := range nodes {
+		feasible[node.GetName()] = true
+	}
+	c := &Consolidation{}
+	for i := range scvList.Items {
+		s := &scvList.Items[i]
+		if !feasible[s.GetName()] || s.Status.CardNumber == 0 || NodeIsIdle(s, l) {
+			continue
+		}
+		if _, _, ok := filter.SelectCards(pod, s, l.ReservedCardMemory(s.GetName(), pod.GetUID())); ok {
+			c.Absorbable = true
+			break
+		}
+	}
+	state.Lock()
+	state.Write(ConsolidationStateKey, c)
+	state.Unlock()
+}
+
+func ReadConsolidation(state *framework.CycleState) *Consolidation {
+	state.RLock()
+	defer state.RUnlock()
+	d, err := state.Read(ConsolidationStateKey)
+	if err != nil {
+		return nil
+	}
+	c, _ := d.(*Consolidation)
+	return c
+}
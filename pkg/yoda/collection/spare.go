@@ -0,0 +1,69 @@
+package collection
+
+import (
+	"sort"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/ledger"
+)
+
+const ColdSparesStateKey = "ColdSpares"
+
+// ColdSpares are idle GPU nodes kept empty for sudden large jobs.
+type ColdSpares map[string]bool
+
+func (c ColdSpares) Clone() framework.StateData {
+	spares := ColdSpares{}
+	for k, v := range c {
+		spares[k] = v
+	}
+	return spares
+}
+
+// NodeIsIdle reports whether every card of the node is free and Yoda has
+// nothing reserved on it.
+func NodeIsIdle(s *scv.Scv, l *ledger.Ledger) bool {
+	if s.Status.CardNumber == 0 || len(l.Node(s.GetName())) > 0 {
+		return false
+	}
+	for _, card := range s.Status.CardList {
+		if card.FreeMemory < card.TotalMemory {
+			return false
+		}
+	}
+	return true
+}
+
+// CollectColdSpares designates the last count idle nodes, by name, as cold spares.
+func CollectColdSpares(state *framework.CycleState, scvList scv.ScvList, l *ledger.Ledger, count int) {
+	var idle []string
+	for i := range scvList.Items {
+		if NodeIsIdle(&scvList.Items[i], l) {
+			idle = append(idle, scvList.Items[i].GetName())
+		}
+	}
+	sort.Strings(idle)
+	if len(idle) > count {
+		idle = idle[len(idle)-count:]
+	}
+	spares := ColdSpares{}
+	for _, name := range idle {
+		spares[name] = true
+	}
+	state.Lock()
+	state.Write(ColdSparesStateKey, spares)
+	state.Unlock()
+}
+
+func ReadColdSpares(state *framework.CycleState) ColdSpares {
+	state.RLock()
+	defer state.RUnlock()
+	d, err := state.Read(ColdSparesStateKey)
+	if err != nil {
+		return nil
+	}
+	spares, _ := d.(ColdSpares)
+	return spares
+}
@@ -0,0 +1,58 @@
+package collection
+
+import (
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/ledger"
+)
+
+const VendorUsageStateKey = "VendorUsage"
+
+// VendorUsage is the percentage of GPU memory in use across each vendor's fleet.
+type VendorUsage map[string]uint64
+
+func (u VendorUsage) Clone() framework.StateData {
+	usage := VendorUsage{}
+	for k, v := range u {
+		usage[k] = v
+	}
+	return usage
+}
+
+// CollectVendorUsage sums the used and reserved memory of every SCV by the vendor of its node.
+func CollectVendorUsage(state *framework.CycleState, scvList scv.ScvList, vendors map[string]string, l *ledger.Ledger) {
+	used := map[string]uint64{}
+	total := map[string]uint64{}
+	for i := range scvList.Items {
+		s := &scvList.Items[i]
+		vendor, ok := vendors[s.GetName()]
+		if !ok || s.Status.TotalMemorySum == 0 {
+			continue
+		}
+		nodeUsed := s.Status.TotalMemorySum - s.Status.FreeMemorySum + l.ReservedMemory(s.GetName())
+		if nodeUsed > s.Status.TotalMemorySum {
+			nodeUsed = s.Status.TotalMemorySum
+		}
+		used[vendor] += nodeUsed
+		total[vendor] += s.Status.TotalMemorySum
+	}
+	usage := VendorUsage{}
+	for vendor, t := range total {
+		usage[vendor] = used[vendor] * 100 / t
+	}
+	state.Lock()
+	state.Write(VendorUsageStateKey, usage)
+	state.Unlock()
+}
+
+func ReadVendorUsage(state *framework.CycleState) VendorUsage {
+	state.RLock()
+	defer state.RUnlock()
+	d, err := state.Read(VendorUsageStateKey)
+	if err != nil {
+		return nil
+	}
+	usage, _ := d.(VendorUsage)
+	return usage
+}
@@ -0,0 +1,137 @@
+package yoda
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/score"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/scvcache"
+)
+
+type NodeBreakdown struct {
+	Node  string          `json:"node"`
+	Total float64         `json:"total"`
+	Terms score.Breakdown `json:"terms"`
+	// Cards are the candidate cards the pod would be given on the node.
+	Cards []int `json:"cards,omitempty"`
+}
+
+// TermDifference is how much more a term gave the preferred node.
+type TermDifference struct {
+	Name       string  `json:"name"`
+	Difference float64 `json:"difference"`
+}
+
+// Comparison explains which of two nodes Yoda prefers for a pod. Deciding holds
+// the largest terms in the preferred node's favour that together cover the gap.
+type Comparison struct {
+	Pod       string           `json:"pod"`
+	A         NodeBreakdown    `json:"a"`
+	B         NodeBreakdown    `json:"b"`
+	Preferred string           `json:"preferred,omitempty"`
+	Deciding  []TermDifference `json:"deciding,omitempty"`
+}
+
+// CompareNodes scores the pod, given as namespace/name, on both nodes against
+// the current SCVs and reports the terms that separate them.
+func (y *Yoda) CompareNodes(ctx context.Context, pod, nodeA, nodeB string) (Comparison, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(pod)
+	if err != nil {
+		return Comparison{}, err
+	}
+	p, err := y.handle.SharedInformerFactory().Core().V1().Pods().Lister().Pods(namespace).Get(name)
+	if err != nil {
+		return Comparison{}, err
+	}
+	ignored, err := score.ParseIgnoredMetrics(p)
+	if err != nil {
+		return Comparison{}, err
+	}
+	state := framework.NewCycleState()
+	state.Write(score.IgnoreMetricsStateKey, ignored)
+	scvList := scv.ScvList{}
+	if err := y.scvs.List(ctx, &scvList); err != nil {
+		return Comparison{}, err
+	}
+	a, err := y.handle.SnapshotSharedLister().NodeInfos().Get(nodeA)
+	if err != nil {
+		return Comparison{}, err
+	}
+	b, err := y.handle.SnapshotSharedLister().NodeInfos().Get(nodeB)
+	if err != nil {
+		return Comparison{}, err
+	}
+	if status := y.collectScoringData(state, p, []*v1.Node{a.Node(), b.Node()}, scvList); !status.IsSuccess() {
+		return Comparison{}, status.AsError()
+	}
+	c := Comparison{Pod: pod}
+	for _, n := range []struct {
+		out  *NodeBreakdown
+		name string
+	}{{&c.A, nodeA}, {&c.B, nodeB}} {
+		info, _ := y.handle.SnapshotSharedLister().NodeInfos().Get(n.name)
+		s := &scv.Scv{}
+		if err := y.scvs.Get(ctx, n.name, scvcache.ReadCache, s); err != nil {
+			return Comparison{}, fmt.Errorf("Get SCV of node %v Error: %v", n.name, err)
+		}
+		filter.ApplyEccReserved(s, info.Node())
+		r := y.breakdown(state, p, info, s)
+		if r.err != nil {
+			return Comparison{}, r.err
+		}
+		var cards []int
+		if r.fits {
+			cards = r.cards
+		}
+		*n.out = NodeBreakdown{Node: n.name, Total: r.b.Total(), Terms: r.b, Cards: cards}
+	}
+	c.Preferred, c.Deciding = decidingTerms(c.A, c.B)
+	return c, nil
+}
+
+func decidingTerms(a, b NodeBreakdown) (string, []TermDifference) {
+	if a.Total == b.Total {
+		return "", nil
+	}
+	preferred, other := a, b
+	if b.Total > a.Total {
+		preferred, other = b, a
+	}
+	scores := map[string]float64{}
+	for _, t := range other.Terms {
+		scores[t.Name] -= t.Score
+	}
+	for _, t := range preferred.Terms {
+		scores[t.Name] += t.Score
+	}
+	var favour []TermDifference
+	for name, d := range scores {
+		if d > 0 {
+			favour = append(favour, TermDifference{Name: name, Difference: d})
+		}
+	}
+	sort.Slice(favour, func(i, j int) bool {
+		if favour[i].Difference != favour[j].Difference {
+			return favour[i].Difference > favour[j].Difference
+		}
+		return favour[i].Name < favour[j].Name
+	})
+	gap := math.Abs(preferred.Total - other.Total)
+	var covered float64
+	for i, d := range favour {
+		covered += d.Difference
+		if covered >= gap {
+			return preferred.Node, favour[:i+1]
+		}
+	}
+	return preferred.Node, favour
+}
@@ -0,0 +1,80 @@
+package yoda
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/score"
+)
+
+func TestCompareNodes(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod", Labels: map[string]string{"scv/memory": "4000"}}}
+	draining := testNode("draining")
+	draining.Annotations = map[string]string{filter.DrainAnnotation: "true"}
+	y := newTestYoda(t, pod, []*v1.Node{testNode("stable"), draining},
+		testScv("stable", testCard(8000, 8000)), testScv("draining", testCard(8000, 8000)))
+	y.args.RespectDrain = filter.RespectDrainPenalize
+	if err := y.handle.SharedInformerFactory().Core().V1().Pods().Informer().GetIndexer().Add(pod); err != nil {
+		t.Fatal(err)
+	}
+	c, err := y.CompareNodes(context.Background(), "default/pod", "draining", "stable")
+	if err != nil {
+		t.Fatalf("CompareNodes() error = %v", err)
+	}
+	if c.A.Node != "draining" || c.B.Node != "stable" || c.A.Total >= c.B.Total {
+		t.Errorf("CompareNodes() totals %v %v, %v %v, want the stable node ahead", c.A.Node, c.A.Total, c.B.Node, c.B.Total)
+	}
+	want := []TermDifference{{Name: "drain", Difference: score.DrainPenalty}}
+	if c.Preferred != "stable" || !reflect.DeepEqual(c.Deciding, want) {
+		t.Errorf("CompareNodes() prefers %v deciding by %v, want stable deciding by %v", c.Preferred, c.Deciding, want)
+	}
+}
+
+func TestDecidingTerms(t *testing.T) {
+	breakdown := func(node string, terms ...score.Term) NodeBreakdown {
+		return NodeBreakdown{Node: node, Total: score.Breakdown(terms).Total(), Terms: terms}
+	}
+	tests := []struct {
+		name          string
+		a, b          NodeBreakdown
+		wantPreferred string
+		wantDeciding  []string
+	}{
+		{
+			name:          "dominant term alone covers the gap",
+			a:             breakdown("a", score.Term{Name: "memory", Score: 0}, score.Term{Name: "clock", Score: 50}),
+			b:             breakdown("b", score.Term{Name: "memory", Score: 5}, score.Term{Name: "clock", Score: 20}),
+			wantPreferred: "a",
+			wantDeciding:  []string{"clock"},
+		},
+		{
+			name:          "terms are added largest first until they cover the gap",
+			a:             breakdown("a", score.Term{Name: "memory", Score: 10}, score.Term{Name: "clock", Score: 10}, score.Term{Name: "drain", Score: -5}, score.Term{Name: "arch", Score: 10}),
+			b:             breakdown("b", score.Term{Name: "memory", Score: 30}, score.Term{Name: "clock", Score: 20}, score.Term{Name: "drain", Score: 0}, score.Term{Name: "arch", Score: 0}),
+			wantPreferred: "b",
+			wantDeciding:  []string{"memory", "clock"},
+		},
+		{
+			name: "equal totals decide nothing",
+			a:    breakdown("a", score.Term{Name: "memory", Score: 10}),
+			b:    breakdown("b", score.Term{Name: "clock", Score: 10}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preferred, deciding := decidingTerms(tt.a, tt.b)
+			var names []string
+			for _, d := range deciding {
+				names = append(names, d.Name)
+			}
+			if preferred != tt.wantPreferred || !reflect.DeepEqual(names, tt.wantDeciding) {
+				t.Errorf("decidingTerms() = %v %v, want %v %v", preferred, names, tt.wantPreferred, tt.wantDeciding)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package yoda
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/score"
+)
+
+type breakdownResult struct {
+	b score.Breakdown
+	// cards are the cards the pod would be given, valid when fits is set.
+	cards []int
+	fits  bool
+	err   error
+}
+
+// breakdownWithin scores the node, giving up once the per-node deadline passes
+// so one slow node cannot hold up the scoring phase. The abandoned computation
+// finishes in the background and its result is dropped; breakdown only
+// returns results, so nothing it computed late reaches the cycle state.
+func (y *Yoda) breakdownWithin(state *framework.CycleState, p *v1.Pod, nodeInfo *nodeinfo.NodeInfo, s *scv.Scv) (breakdownResult, bool) {
+	if y.args.PerNodeScoreDeadlineMs <= 0 {
+		return y.breakdown(state, p, nodeInfo, s), true
+	}
+	done := make(chan breakdownResult, 1)
+	go func() {
+		done <- y.breakdown(state, p, nodeInfo, s)
+	}()
+	deadline := time.Duration(y.args.PerNodeScoreDeadlineMs) * time.Millisecond
+	select {
+	case r := <-done:
+		return r, true
+	case <-y.clock.After(deadline):
+		klog.Warningf("scoring pod %v on node %v exceeded %v, using neutral score %v", p.Name, nodeInfo.Node().GetName(), deadline, y.args.NeutralScore)
+		return breakdownResult{}, false
+	}
+}
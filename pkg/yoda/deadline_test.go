@@ -0,0 +1,88 @@
+package yoda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/scvcache"
+)
+
+func TestBreakdownWithinDeadline(t *testing.T) {
+	ctx := context.Background()
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod", Labels: map[string]string{"scv/memory": "4000"}}}
+	node := testNode("node")
+	y := newTestYoda(t, pod, []*v1.Node{node}, testScv("node", testCard(8000, 8000)))
+	y.args.PerNodeScoreDeadlineMs = 10
+	state := framework.NewCycleState()
+	if status := y.PreFilter(ctx, state, pod); !status.IsSuccess() {
+		t.Fatalf("PreFilter() = %v", status.Message())
+	}
+	if status := y.PostFilter(ctx, state, pod, []*v1.Node{node}, nil); !status.IsSuccess() {
+		t.Fatalf("PostFilter() = %v", status.Message())
+	}
+	nodeInfo, err := y.handle.SnapshotSharedLister().NodeInfos().Get("node")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &scv.Scv{}
+	if err := y.scvs.Get(ctx, "node", scvcache.ReadLive, s); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("slow node", func(t *testing.T) {
+		fake := clock.NewFakeClock(time.Now())
+		y.clock = fake
+		defer func() { y.clock = clock.RealClock{} }()
+		// Holding the state lock stalls breakdown on its first state read.
+		state.Lock()
+		type result struct {
+			r  breakdownResult
+			ok bool
+		}
+		done := make(chan result, 1)
+		go func() {
+			r, ok := y.breakdownWithin(state, pod, nodeInfo, s)
+			done <- result{r, ok}
+		}()
+		if err := wait.PollImmediate(time.Millisecond, time.Second, func() (bool, error) {
+			return fake.HasWaiters(), nil
+		}); err != nil {
+			state.Unlock()
+			t.Fatal("breakdownWithin is not waiting on the deadline")
+		}
+		fake.Step(10 * time.Millisecond)
+		got := <-done
+		state.Unlock()
+		if got.ok || got.r.b != nil {
+			t.Fatalf("breakdownWithin() = %v, %v, want it to give up", got.r.b, got.ok)
+		}
+		// Let the abandoned breakdown finish, it must not record its cards.
+		time.Sleep(20 * time.Millisecond)
+		if cards, ok := readCandidateCards(state, "node"); ok {
+			t.Errorf("late breakdown recorded candidate cards %v", cards)
+		}
+	})
+
+	t.Run("node within the deadline", func(t *testing.T) {
+		y.args.PerNodeScoreDeadlineMs = int(time.Minute / time.Millisecond)
+		score, status := y.Score(ctx, state, pod, "node")
+		if !status.IsSuccess() {
+			t.Fatalf("Score() = %v", status.Message())
+		}
+		if score == 0 {
+			t.Error("Score() returned the neutral score for a fast node")
+		}
+		if cards, ok := readCandidateCards(state, "node"); !ok || len(cards) != 1 {
+			t.Errorf("candidate cards = %v, %v, want one card", cards, ok)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package yoda
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"sync"
+
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/score"
+)
+
+const (
+	// DebugAnnotation asks Yoda to write the chosen node's score breakdown onto the pod.
+	DebugAnnotation             = "yoda.gpu/debug"
+	ChosenScoreDetailAnnotation = "yoda.gpu/chosen-score-detail"
+	ScoreDetailsStateKey        = "ScoreDetails"
+
+	maxScoreDetailBytes = 2048
+)
+
+// scoreDetails holds the raw score breakdown of every node scored for a debug pod.
+type scoreDetails struct {
+	mu    sync.Mutex
+	nodes map[string]score.Breakdown
+}
+
+func (d *scoreDetails) Clone() framework.StateData {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	nodes := map[string]score.Breakdown{}
+	for k, v := range d.nodes {
+		nodes[k] = append(score.Breakdown(nil), v...)
+	}
+	return &scoreDetails{nodes: nodes}
+}
+
+type scoreDetail struct {
+	Node      string          `json:"node"`
+	Total     float64         `json:"total"`
+	Terms     score.Breakdown `json:"terms"`
+	Cards     []int           `json:"cards,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"`
+}
+
+func recordScoreDetail(state *framework.CycleState, nodeName string, b score.Breakdown) {
+	state.Lock()
+	d, err := state.Read(ScoreDetailsStateKey)
+	details, ok := d.(*scoreDetails)
+	if err != nil || !ok {
+		details = &scoreDetails{nodes: map[string]score.Breakdown{}}
+		state.Write(ScoreDetailsStateKey, details)
+	}
+	state.Unlock()
+	details.mu.Lock()
+	details.nodes[nodeName] = b
+	details.mu.Unlock()
+}
+
+// chosenScoreDetail renders the raw breakdown of the chosen node, leaving out
+// zero terms and then the smallest ones until it fits in the annotation budget.
+func chosenScoreDetail(state *framework.CycleState, nodeName string) (string, bool) {
+	state.RLock()
+	d, err := state.Read(ScoreDetailsStateKey)
+	state.RUnlock()
+	details, ok := d.(*scoreDetails)
+	if err != nil || !ok {
+		return "", false
+	}
+	details.mu.Lock()
+	b, ok := details.nodes[nodeName]
+	details.mu.Unlock()
+	if !ok {
+		return "", false
+	}
+	cards, _ := readCandidateCards(state, nodeName)
+	detail := scoreDetail{Node: nodeName, Total: b.Total(), Cards: cards}
+	for _, t := range b {
+		if t.Score != 0 {
+			detail.Terms = append(detail.Terms, t)
+		}
+	}
+	sort.SliceStable(detail.Terms, func(i, j int) bool {
+		return math.Abs(detail.Terms[i].Score) > math.Abs(detail.Terms[j].Score)
+	})
+	for {
+		data, err := json.Marshal(detail)
+		if err != nil {
+			return "", false
+		}
+		if len(data) <= maxScoreDetailBytes || len(detail.Terms) == 0 {
+			return string(data), true
+		}
+		detail.Terms = detail.Terms[:len(detail.Terms)-1]
+		detail.Truncated = true
+	}
+}
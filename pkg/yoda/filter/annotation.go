@@ -0,0 +1,61 @@
+package filter
+
+import (
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// CardsAnnotation lists the indexes of the cards assigned to a bound pod.
+const CardsAnnotation = "yoda.gpu/cards"
+
+// CardAnnotation reads the value for the card at index from a node annotation
+// holding one comma-separated entry per card, in SCV card list order.
+func CardAnnotation(node *v1.Node, key string, index int) (string, bool) {
+	if node == nil {
+		return "", false
+	}
+	value, ok := node.GetAnnotations()[key]
+	if !ok {
+		return "", false
+	}
+	values := strings.Split(value, ",")
+	if index < 0 || index >= len(values) {
+		return "", false
+	}
+	v := strings.TrimSpace(values[index])
+	return v, v != ""
+}
+
+func PodAnnotationIsTrue(pod *v1.Pod, key string) bool {
+	return strings.EqualFold(pod.GetAnnotations()[key], "true")
+}
+
+func PodCards(pod *v1.Pod) []int {
+	value, ok := pod.GetAnnotations()[CardsAnnotation]
+	if !ok || value == "" {
+		return nil
+	}
+	var cards []int
+	for _, v := range strings.Split(value, ",") {
+		if card, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			cards = append(cards, card)
+		}
+	}
+	return cards
+}
+
+// CardOccupants returns the pods on the node assigned to the card at index.
+func CardOccupants(pods []*v1.Pod, index int) []*v1.Pod {
+	var occupants []*v1.Pod
+	for _, pod := range pods {
+		for _, card := range PodCards(pod) {
+			if card == index {
+				occupants = append(occupants, pod)
+				break
+			}
+		}
+	}
+	return occupants
+}
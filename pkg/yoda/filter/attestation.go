@@ -0,0 +1,29 @@
+package filter
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	RequireAttestationAnnotation = "yoda.gpu/require-attestation"
+	// AttestationAnnotation is set on nodes by the attestation agent.
+	AttestationAnnotation = "yoda.gpu/attestation"
+	// AttestedAtAnnotation holds the RFC3339 time of the node's last attestation.
+	AttestedAtAnnotation = "yoda.gpu/attested-at"
+
+	AttestationVerified = "verified"
+)
+
+func NodeAttested(node *v1.Node) bool {
+	return node != nil && node.GetAnnotations()[AttestationAnnotation] == AttestationVerified
+}
+
+func NodeAttestedAt(node *v1.Node) (time.Time, bool) {
+	if !NodeAttested(node) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, node.GetAnnotations()[AttestedAtAnnotation])
+	return t, err == nil
+}
@@ -0,0 +1,27 @@
+package filter
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	LockClocksAnnotation = "yoda.gpu/lock-clocks"
+	// ClockLockingAnnotation is set to supported on nodes that permit locking GPU clocks.
+	ClockLockingAnnotation = "yoda.gpu/clock-locking"
+	// CardLockedClockAnnotation lists the clock each card is locked to, comma-separated, empty when unlocked.
+	CardLockedClockAnnotation = "yoda.gpu/card-locked-clock"
+
+	ClockLockingSupported = "supported"
+)
+
+func NodeSupportsClockLocking(node *v1.Node) bool {
+	return node != nil && strings.EqualFold(node.GetAnnotations()[ClockLockingAnnotation], ClockLockingSupported)
+}
+
+// CardLockedClock returns the clock the card at index is locked to, or zero.
+func CardLockedClock(node *v1.Node, index int) uint {
+	value, _ := CardAnnotation(node, CardLockedClockAnnotation, index)
+	return strToUint(value)
+}
@@ -0,0 +1,55 @@
+package filter
+
+import (
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+)
+
+// CpuPerGpuAnnotation is the number of CPUs the pod needs to feed each GPU card.
+const CpuPerGpuAnnotation = "yoda.gpu/cpu-per-gpu"
+
+func PodCpuPerGpu(pod *v1.Pod) (float64, bool) {
+	value, ok := pod.GetAnnotations()[CpuPerGpuAnnotation]
+	if !ok {
+		return 0, false
+	}
+	cpus, err := strconv.ParseFloat(value, 64)
+	if err != nil || cpus <= 0 {
+		return 0, false
+	}
+	return cpus, true
+}
+
+// NodeCpuPerFreeGpu returns the node's unrequested CPUs per idle healthy card.
+// Nodes without an idle card report nothing.
+func NodeCpuPerFreeGpu(node *nodeinfo.NodeInfo, s *scv.Scv) (float64, bool) {
+	var free int
+	for _, card := range s.Status.CardList {
+		if card.Health == "Healthy" && card.FreeMemory >= card.TotalMemory {
+			free++
+		}
+	}
+	if free == 0 {
+		return 0, false
+	}
+	milliCPU := node.AllocatableResource().MilliCPU - node.RequestedResource().MilliCPU
+	if milliCPU < 0 {
+		milliCPU = 0
+	}
+	return float64(milliCPU) / 1000 / float64(free), true
+}
+
+func NewCpuRatioPredicate() Predicate {
+	return NewPredicate("cpu-ratio", func(pod *v1.Pod, node *nodeinfo.NodeInfo, s *scv.Scv) (bool, string) {
+		want, ok := PodCpuPerGpu(pod)
+		if !ok {
+			return true, ""
+		}
+		have, ok := NodeCpuPerFreeGpu(node, s)
+		return !ok || have >= want, "insufficient free CPU per GPU card"
+	})
+}
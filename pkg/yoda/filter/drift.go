@@ -0,0 +1,57 @@
+package filter
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/ledger"
+)
+
+// DriftedCards returns, for each card whose SCV-reported usage exceeds the
+// memory reserved on it by more than tolerance MB, by how much it does.
+func DriftedCards(s *scv.Scv, reserved map[int]uint64, tolerance uint64) map[int]uint64 {
+	drifted := map[int]uint64{}
+	for i, card := range s.Status.CardList {
+		if card.FreeMemory > card.TotalMemory {
+			continue
+		}
+		used := card.TotalMemory - card.FreeMemory
+		if used > reserved[i]+tolerance {
+			drifted[i] = used - reserved[i]
+		}
+	}
+	return drifted
+}
+
+// BlockCards returns a copy of reserved under which the given cards fit nothing.
+func BlockCards(s *scv.Scv, reserved map[int]uint64, cards map[int]uint64) map[int]uint64 {
+	blocked := map[int]uint64{}
+	for i, memory := range reserved {
+		blocked[i] = memory
+	}
+	for i := range cards {
+		if i < len(s.Status.CardList) {
+			blocked[i] = s.Status.CardList[i].TotalMemory + 1
+		}
+	}
+	return blocked
+}
+
+// NewDriftPredicate treats SCV usage the ledger cannot account for as
+// authoritative and keeps the pod off those cards.
+func NewDriftPredicate(l *ledger.Ledger, tolerance uint64) Predicate {
+	return NewPredicate("drift", func(pod *v1.Pod, node *nodeinfo.NodeInfo, s *scv.Scv) (bool, string) {
+		drifted := DriftedCards(s, l.ReservedCardMemory(s.GetName(), pod.GetUID()), tolerance)
+		for i, drift := range drifted {
+			klog.V(2).Infof("node %v card %v: SCV reports %vMB more in use than reserved", s.GetName(), i, drift)
+		}
+		if len(drifted) == 0 {
+			return true, ""
+		}
+		_, _, ok := SelectCards(pod, s, BlockCards(s, nil, drifted))
+		return ok, "GPU cards are used beyond what is reserved on them"
+	})
+}
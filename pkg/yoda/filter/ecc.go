@@ -0,0 +1,39 @@
+package filter
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+)
+
+// CardEccReservedAnnotation lists the memory ECC reserves on each card of the
+// node, comma-separated, for cards whose SCV memory is the raw total.
+const CardEccReservedAnnotation = "yoda.gpu/card-ecc-reserved"
+
+// ApplyEccReserved takes the memory ECC reserves out of the SCV's card and node
+// totals in place, so fitting and scoring see only usable memory.
+func ApplyEccReserved(s *scv.Scv, node *v1.Node) {
+	if _, ok := node.GetAnnotations()[CardEccReservedAnnotation]; !ok {
+		return
+	}
+	var total, free uint64
+	for i := range s.Status.CardList {
+		card := &s.Status.CardList[i]
+		if value, ok := CardAnnotation(node, CardEccReservedAnnotation, i); ok {
+			reserved := ParseMemory(value)
+			card.TotalMemory = subtractMemory(card.TotalMemory, reserved)
+			card.FreeMemory = subtractMemory(card.FreeMemory, reserved)
+		}
+		total += card.TotalMemory
+		free += card.FreeMemory
+	}
+	s.Status.TotalMemorySum = total
+	s.Status.FreeMemorySum = free
+}
+
+func subtractMemory(memory, reserved uint64) uint64 {
+	if reserved > memory {
+		return 0
+	}
+	return memory - reserved
+}
@@ -0,0 +1,39 @@
+package filter
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	ElasticMinAnnotation = "yoda.gpu/elastic-min"
+	ElasticMaxAnnotation = "yoda.gpu/elastic-max"
+	// ElasticGrantedAnnotation records how many cards an elastic pod was given.
+	ElasticGrantedAnnotation = "yoda.gpu/elastic-granted"
+)
+
+// PodElasticRange returns the card range of an elastic pod. A maximum below the
+// minimum is treated as the minimum.
+func PodElasticRange(pod *v1.Pod) (uint, uint, bool) {
+	value, ok := pod.GetAnnotations()[ElasticMinAnnotation]
+	if !ok {
+		return 0, 0, false
+	}
+	min := strToUint(value)
+	if min == 0 {
+		return 0, 0, false
+	}
+	max := strToUint(pod.GetAnnotations()[ElasticMaxAnnotation])
+	if max < min {
+		max = min
+	}
+	return min, max, true
+}
+
+// ElasticGrant caps the number of fitting cards at the pod's maximum.
+func ElasticGrant(pod *v1.Pod, fitting uint) uint {
+	_, max, ok := PodElasticRange(pod)
+	if !ok || fitting < max {
+		return fitting
+	}
+	return max
+}
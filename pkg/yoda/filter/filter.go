@@ -1,24 +1,106 @@
 package filter
 
 import (
+	"fmt"
+	"sort"
 	"strconv"
+	"strings"
+	"sync/atomic"
 
 	v1 "k8s.io/api/core/v1"
 
 	scv "github.com/NJUPT-ISL/SCV/api/v1"
 )
 
+const (
+	ModelSizeAnnotation = "yoda.gpu/model-size"
+	CopiesAnnotation    = "yoda.gpu/copies"
+	ComputeAnnotation   = "yoda.gpu/compute-percent"
+	// MemoryPercentOfMaxAnnotation asks for a share of the node's largest card.
+	MemoryPercentOfMaxAnnotation = "yoda.gpu/memory-percent-of-max"
+
+	HealthyRemainderAnnotation = "yoda.gpu/require-node-healthy-remainder"
+	BandwidthHeavyAnnotation   = "yoda.gpu/bandwidth-heavy"
+
+	// WorkloadClassLabel is interactive for notebooks and batch for throughput jobs.
+	WorkloadClassLabel = "yoda.gpu/workload-class"
+	ClassInteractive   = "interactive"
+	ClassBatch         = "batch"
+)
+
+func PodWorkloadClass(pod *v1.Pod) string {
+	return strings.ToLower(pod.GetLabels()[WorkloadClassLabel])
+}
+
+// unrequestedNoGpu is 1 when pods that state no GPU requirement ask for no cards
+// rather than the single card they default to.
+var unrequestedNoGpu int32
+
+func SetUnrequestedNoGpu(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&unrequestedNoGpu, v)
+}
+
 func PodFitsNumber(pod *v1.Pod, scv *scv.Scv) (bool, uint) {
-	if number, ok := pod.GetLabels()["scv/number"]; ok {
+	if min, _, ok := PodElasticRange(pod); ok {
+		return min <= scv.Status.CardNumber, min
+	}
+	if number := PodRequirement(pod).Number; number != "" {
 		return strToUint(number) <= scv.Status.CardNumber, strToUint(number)
 	}
+	if unrequested(pod) {
+		return true, 0
+	}
 	return scv.Status.CardNumber > 0, 1
 }
 
+// PodCardCount is the most cards the pod can be given on any node.
+func PodCardCount(pod *v1.Pod) uint {
+	if _, max, ok := PodElasticRange(pod); ok {
+		return max
+	}
+	if number := PodRequirement(pod).Number; number != "" {
+		return strToUint(number)
+	}
+	if unrequested(pod) {
+		return 0
+	}
+	return 1
+}
+
+// PodRequestsNoGpu reports whether the pod asks for zero cards, explicitly or,
+// when unrequested pods get no GPU, by stating no GPU requirement at all.
+func PodRequestsNoGpu(pod *v1.Pod) bool {
+	if number := PodRequirement(pod).Number; number != "" {
+		return strToUint(number) == 0
+	}
+	return unrequested(pod)
+}
+
+func unrequested(pod *v1.Pod) bool {
+	return atomic.LoadInt32(&unrequestedNoGpu) == 1 && !PodStatesGpuRequirement(pod)
+}
+
+// PodStatesGpuRequirement reports whether any registered parser or GPU
+// annotation yields a requirement for the pod.
+func PodStatesGpuRequirement(pod *v1.Pod) bool {
+	if _, _, ok := PodElasticRange(pod); ok {
+		return true
+	}
+	for _, key := range []string{ModelSizeAnnotation, MemoryPercentOfMaxAnnotation, ComputeAnnotation} {
+		if _, ok := pod.GetAnnotations()[key]; ok {
+			return true
+		}
+	}
+	return anyParserYieldsRequirement(pod)
+}
+
 func PodFitsMemory(number uint, pod *v1.Pod, scv *scv.Scv) (bool, uint64) {
-	if memory, ok := pod.GetLabels()["scv/memory"]; ok {
+	if m, ok := PodMemory(pod, scv); ok {
 		fitsCard := uint(0)
-		m := StrToUint64(memory)
 		for _, card := range scv.Status.CardList {
 			if CardFitsMemory(m, card) {
 				fitsCard++
@@ -32,8 +114,64 @@ func PodFitsMemory(number uint, pod *v1.Pod, scv *scv.Scv) (bool, uint64) {
 	return true, 0
 }
 
+// PodMemory returns the GPU memory in MB the pod requires on each card of the
+// node. A requirement relative to the largest card needs the node's SCV.
+func PodMemory(pod *v1.Pod, scv *scv.Scv) (uint64, bool) {
+	if memory := PodRequirement(pod).Memory; memory != "" {
+		return ParseMemory(memory), true
+	}
+	if size, copies, ok := PodModelCopies(pod); ok {
+		return size * copies, true
+	}
+	if percent, ok, err := PodMemoryPercentOfMax(pod); ok && err == nil && scv != nil {
+		var largest uint64
+		for _, card := range scv.Status.CardList {
+			if card.TotalMemory > largest {
+				largest = card.TotalMemory
+			}
+		}
+		return uint64(float64(largest) * percent / 100), true
+	}
+	return 0, false
+}
+
+// PodMemoryPercentOfMax returns the share of the node's largest card the pod
+// asked for, and an error when the annotation is not a valid percentage.
+func PodMemoryPercentOfMax(pod *v1.Pod) (float64, bool, error) {
+	value, ok := pod.GetAnnotations()[MemoryPercentOfMaxAnnotation]
+	if !ok {
+		return 0, false, nil
+	}
+	percent, err := ParseMemoryPercent(value)
+	if err != nil {
+		return 0, true, fmt.Errorf("%v: %v", MemoryPercentOfMaxAnnotation, err)
+	}
+	return percent, true, nil
+}
+
+func PodModelCopies(pod *v1.Pod) (uint64, uint64, bool) {
+	size, ok := pod.GetAnnotations()[ModelSizeAnnotation]
+	if !ok {
+		return 0, 0, false
+	}
+	copies := uint64(1)
+	if c, ok := pod.GetAnnotations()[CopiesAnnotation]; ok && StrToUint64(c) > 0 {
+		copies = StrToUint64(c)
+	}
+	return ParseMemory(size), copies, true
+}
+
+// PodCompute returns the percentage of each card's compute the pod declared, or zero.
+func PodCompute(pod *v1.Pod) uint {
+	compute := strToUint(pod.GetAnnotations()[ComputeAnnotation])
+	if compute > 100 {
+		return 100
+	}
+	return compute
+}
+
 func PodFitsClock(number uint, pod *v1.Pod, scv *scv.Scv) (bool, uint) {
-	if clock, ok := pod.GetLabels()["scv/clock"]; ok {
+	if clock := PodRequirement(pod).Clock; clock != "" {
 		fitsCard := uint(0)
 		c := strToUint(clock)
 		for _, card := range scv.Status.CardList {
@@ -49,6 +187,23 @@ func PodFitsClock(number uint, pod *v1.Pod, scv *scv.Scv) (bool, uint) {
 	return true, 0
 }
 
+// PodFitsHealthyRemainder checks that the node keeps at least the requested
+// number of other healthy cards once the pod's cards are taken.
+func PodFitsHealthyRemainder(pod *v1.Pod, scv *scv.Scv) bool {
+	value, ok := pod.GetAnnotations()[HealthyRemainderAnnotation]
+	if !ok {
+		return true
+	}
+	_, number := PodFitsNumber(pod, scv)
+	var healthy uint
+	for _, card := range scv.Status.CardList {
+		if card.Health == "Healthy" {
+			healthy++
+		}
+	}
+	return healthy >= number+strToUint(value)
+}
+
 func CardFitsMemory(memory uint64, card scv.Card) bool {
 	return card.Health == "Healthy" && card.FreeMemory >= memory
 }
@@ -57,6 +212,12 @@ func CardFitsClock(clock uint, card scv.Card) bool {
 	return card.Health == "Healthy" && card.Clock == clock
 }
 
+// CardFits reports whether a card can serve a per-card memory and clock
+// requirement, where zero means the pod did not ask for it.
+func CardFits(memory uint64, clock uint, card scv.Card) bool {
+	return CardFitsMemory(memory, card) && (clock == 0 || CardFitsClock(clock, card))
+}
+
 func strToUint(str string) uint {
 	if i, e := strconv.Atoi(str); e != nil {
 		return 0
@@ -84,3 +245,30 @@ func StrToInt64(str string) int64 {
 func Uint64ToInt64(intNum uint64) int64 {
 	return StrToInt64(strconv.FormatUint(intNum, 10))
 }
+
+// SelectCards picks the cards the pod will use on the node, preferring the ones
+// with the most free memory. reserved holds memory already promised on each card.
+func SelectCards(pod *v1.Pod, scv *scv.Scv, reserved map[int]uint64) ([]int, uint64, bool) {
+	ok, number := PodFitsNumber(pod, scv)
+	if !ok {
+		return nil, 0, false
+	}
+	_, memory := PodFitsMemory(number, pod, scv)
+	_, clock := PodFitsClock(number, pod, scv)
+	var cards []int
+	for i, card := range scv.Status.CardList {
+		if CardFits(memory+reserved[i], clock, card) {
+			cards = append(cards, i)
+		}
+	}
+	if uint(len(cards)) < number {
+		return nil, 0, false
+	}
+	if _, _, ok := PodElasticRange(pod); ok {
+		number = ElasticGrant(pod, uint(len(cards)))
+	}
+	sort.SliceStable(cards, func(i, j int) bool {
+		return scv.Status.CardList[cards[i]].FreeMemory > scv.Status.CardList[cards[j]].FreeMemory
+	})
+	return cards[:number], memory, true
+}
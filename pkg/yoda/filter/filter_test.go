@@ -0,0 +1,682 @@
+package filter
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/ledger"
+)
+
+func testPod(labels, annotations map[string]string) *v1.Pod {
+	return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", Labels: labels, Annotations: annotations}}
+}
+
+func testScv(name string, cards ...scv.Card) *scv.Scv {
+	s := &scv.Scv{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	for _, card := range cards {
+		s.Status.CardList = append(s.Status.CardList, card)
+		s.Status.TotalMemorySum += card.TotalMemory
+		s.Status.FreeMemorySum += card.FreeMemory
+		s.Status.CardNumber++
+	}
+	return s
+}
+
+func testCard(free, total uint64) scv.Card {
+	return scv.Card{Health: "Healthy", TotalMemory: total, FreeMemory: free, Clock: 1500}
+}
+
+func TestPodModelCopiesMemory(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		card        scv.Card
+		memory      uint64
+		fits        bool
+	}{
+		{
+			name:        "three 5GB copies need 15GB and miss a 12GB free card",
+			annotations: map[string]string{ModelSizeAnnotation: "5GB", CopiesAnnotation: "3"},
+			card:        testCard(12*1024, 16*1024),
+			memory:      15 * 1024,
+			fits:        false,
+		},
+		{
+			name:        "three 5GB copies fit a 16GB free card",
+			annotations: map[string]string{ModelSizeAnnotation: "5GB", CopiesAnnotation: "3"},
+			card:        testCard(16*1024, 16*1024),
+			memory:      15 * 1024,
+			fits:        true,
+		},
+		{
+			name:        "copies default to one",
+			annotations: map[string]string{ModelSizeAnnotation: "5GB"},
+			card:        testCard(12*1024, 16*1024),
+			memory:      5 * 1024,
+			fits:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := testPod(nil, tt.annotations)
+			s := testScv("node", tt.card)
+			fits, memory := PodFitsMemory(1, pod, s)
+			if memory != tt.memory || fits != tt.fits {
+				t.Errorf("PodFitsMemory() = %v, %v, want %v, %v", fits, memory, tt.fits, tt.memory)
+			}
+		})
+	}
+}
+
+func TestPodRequestsNoGpu(t *testing.T) {
+	gpuLimit := testPod(nil, nil)
+	gpuLimit.Spec.Containers = []v1.Container{{Resources: v1.ResourceRequirements{Limits: v1.ResourceList{GpuResource: resource.MustParse("1")}}}}
+	tests := []struct {
+		name        string
+		pod         *v1.Pod
+		unrequested bool
+		want        bool
+		wantNumber  uint
+	}{
+		{name: "explicit zero", pod: testPod(map[string]string{"scv/number": "0"}, nil), want: true, wantNumber: 0},
+		{name: "no requirement defaults to one card", pod: testPod(nil, nil), want: false, wantNumber: 1},
+		{name: "no requirement requests no card when configured", pod: testPod(nil, nil), unrequested: true, want: true, wantNumber: 0},
+		{name: "memory label is a GPU request", pod: testPod(map[string]string{"scv/memory": "1000"}, nil), unrequested: true, want: false, wantNumber: 1},
+		{name: "model size is a GPU request", pod: testPod(nil, map[string]string{ModelSizeAnnotation: "5GB"}), unrequested: true, want: false, wantNumber: 1},
+		{name: "GPU limit outside the parser chain is a GPU request", pod: gpuLimit, unrequested: true, want: false, wantNumber: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetUnrequestedNoGpu(tt.unrequested)
+			defer SetUnrequestedNoGpu(false)
+			if got := PodRequestsNoGpu(tt.pod); got != tt.want {
+				t.Errorf("PodRequestsNoGpu() = %v, want %v", got, tt.want)
+			}
+			if _, number := PodFitsNumber(tt.pod, testScv("node", testCard(8000, 8000))); number != tt.wantNumber {
+				t.Errorf("PodFitsNumber() number = %v, want %v", number, tt.wantNumber)
+			}
+		})
+	}
+}
+
+func TestGpuIsolationPredicate(t *testing.T) {
+	SetUnrequestedNoGpu(true)
+	defer SetUnrequestedNoGpu(false)
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+		scv  *scv.Scv
+		want bool
+	}{
+		{name: "non-GPU pod on a GPU node", pod: testPod(nil, nil), scv: testScv("gpu", testCard(8000, 8000)), want: false},
+		{name: "non-GPU pod on a node without GPUs", pod: testPod(nil, nil), scv: testScv("cpu"), want: true},
+		{name: "GPU pod on a GPU node", pod: testPod(map[string]string{"scv/number": "1"}, nil), scv: testScv("gpu", testCard(8000, 8000)), want: true},
+	}
+	p := NewGpuIsolationPredicate()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := p.Check(tt.pod, nil, tt.scv); got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPodMemoryPercentOfMax(t *testing.T) {
+	small := testScv("small", testCard(16*1024, 16*1024))
+	large := testScv("large", testCard(40*1024, 40*1024), testCard(20*1024, 24*1024))
+	tests := []struct {
+		name    string
+		percent string
+		small   uint64
+		large   uint64
+		wantErr bool
+	}{
+		{name: "plain number", percent: "50", small: 8 * 1024, large: 20 * 1024},
+		{name: "trailing percent sign", percent: "50%", small: 8 * 1024, large: 20 * 1024},
+		{name: "fraction", percent: "12.5%", small: 2 * 1024, large: 5 * 1024},
+		{name: "whole card", percent: "100", small: 16 * 1024, large: 40 * 1024},
+		{name: "above 100", percent: "150", wantErr: true},
+		{name: "negative", percent: "-10", wantErr: true},
+		{name: "not a number", percent: "half", wantErr: true},
+		{name: "empty", percent: "", wantErr: true},
+		{name: "double percent sign", percent: "50%%", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := testPod(nil, map[string]string{MemoryPercentOfMaxAnnotation: tt.percent})
+			if _, ok, err := PodMemoryPercentOfMax(pod); !ok || (err != nil) != tt.wantErr {
+				t.Fatalf("PodMemoryPercentOfMax() = %v, %v, want error %v", ok, err, tt.wantErr)
+			}
+			m, ok := PodMemory(pod, small)
+			if ok == tt.wantErr || m != tt.small {
+				t.Errorf("PodMemory(small) = %v, %v, want %v", m, ok, tt.small)
+			}
+			m, ok = PodMemory(pod, large)
+			if ok == tt.wantErr || m != tt.large {
+				t.Errorf("PodMemory(large) = %v, %v, want %v", m, ok, tt.large)
+			}
+		})
+	}
+}
+
+func gpuContainer(number, memory string) v1.Container {
+	limits := v1.ResourceList{}
+	if number != "" {
+		limits[GpuResource] = resource.MustParse(number)
+	}
+	if memory != "" {
+		limits[GpuMemoryResource] = resource.MustParse(memory)
+	}
+	return v1.Container{Resources: v1.ResourceRequirements{Limits: limits}}
+}
+
+func TestResourceLimitParser(t *testing.T) {
+	tests := []struct {
+		name       string
+		init       []v1.Container
+		containers []v1.Container
+		policy     string
+		want       Requirement
+	}{
+		{
+			name:       "main containers share the phase",
+			containers: []v1.Container{gpuContainer("2", "10240"), gpuContainer("1", "8192")},
+			policy:     InitContainerPeak,
+			want:       Requirement{Number: "3", Memory: "8192"},
+		},
+		{
+			name:       "per-card memory rounds up",
+			containers: []v1.Container{gpuContainer("3", "10000")},
+			policy:     InitContainerPeak,
+			want:       Requirement{Number: "3", Memory: "3334"},
+		},
+		{
+			name:       "peak takes the heavy init card with the main card count",
+			init:       []v1.Container{gpuContainer("1", "20480")},
+			containers: []v1.Container{gpuContainer("2", "10240")},
+			policy:     InitContainerPeak,
+			want:       Requirement{Number: "2", Memory: "20480"},
+		},
+		{
+			name:       "peak takes a wider init phase",
+			init:       []v1.Container{gpuContainer("4", "4096")},
+			containers: []v1.Container{gpuContainer("2", "10240")},
+			policy:     InitContainerPeak,
+			want:       Requirement{Number: "4", Memory: "5120"},
+		},
+		{
+			name:       "sum adds the init cards and keeps the largest per-card memory",
+			init:       []v1.Container{gpuContainer("1", "20480")},
+			containers: []v1.Container{gpuContainer("2", "10240")},
+			policy:     InitContainerSum,
+			want:       Requirement{Number: "3", Memory: "20480"},
+		},
+		{
+			name:       "memory without a count is one card",
+			containers: []v1.Container{gpuContainer("", "6144")},
+			policy:     InitContainerPeak,
+			want:       Requirement{Memory: "6144"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := testPod(nil, nil)
+			pod.Spec.InitContainers = tt.init
+			pod.Spec.Containers = tt.containers
+			got, ok := NewResourceLimitParser(tt.policy).Parse(pod)
+			if !ok || got != tt.want {
+				t.Errorf("Parse() = %+v, %v, want %+v", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestKubeflowParser(t *testing.T) {
+	tests := []struct {
+		name       string
+		labels     map[string]string
+		containers []v1.Container
+		want       Requirement
+		wantOk     bool
+	}{
+		{
+			name: "PyTorchJob master",
+			labels: map[string]string{
+				"training.kubeflow.org/job-name":      "bert",
+				"training.kubeflow.org/operator-name": "pytorchjob-controller",
+				"training.kubeflow.org/replica-type":  "master",
+				"training.kubeflow.org/job-role":      "master",
+			},
+			containers: []v1.Container{gpuContainer("2", "32768")},
+			want:       Requirement{Number: "2", Memory: "16384"},
+			wantOk:     true,
+		},
+		{
+			name: "PyTorchJob worker from an older operator",
+			labels: map[string]string{
+				"group-name":           "kubeflow.org",
+				"pytorch-job-name":     "bert",
+				"pytorch-replica-type": "worker",
+			},
+			containers: []v1.Container{gpuContainer("1", "")},
+			want:       Requirement{Number: "1"},
+			wantOk:     true,
+		},
+		{
+			name: "MPIJob launcher",
+			labels: map[string]string{
+				"training.kubeflow.org/job-name":      "horovod",
+				"training.kubeflow.org/operator-name": "mpi-operator",
+				"training.kubeflow.org/job-role":      "launcher",
+			},
+			containers: []v1.Container{{Name: "mpirun"}},
+			want:       Requirement{Number: "0"},
+			wantOk:     true,
+		},
+		{
+			name:       "MPIJob worker",
+			labels:     map[string]string{"mpi-job-name": "horovod", "mpi-job-role": "worker"},
+			containers: []v1.Container{gpuContainer("4", "65536")},
+			want:       Requirement{Number: "4", Memory: "16384"},
+			wantOk:     true,
+		},
+		{
+			name:       "pod outside Kubeflow",
+			labels:     map[string]string{"app": "bert"},
+			containers: []v1.Container{gpuContainer("2", "32768")},
+		},
+	}
+	p := NewKubeflowParser(InitContainerPeak)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := testPod(tt.labels, nil)
+			pod.Spec.Containers = tt.containers
+			got, ok := p.Parse(pod)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("Parse() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestPodFitsScratch(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+			v1.ResourceEphemeralStorage: resource.MustParse("100Gi"),
+		}},
+	}
+	running := &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{
+		Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+			v1.ResourceEphemeralStorage: resource.MustParse("40Gi"),
+		}},
+	}}}}
+	info := nodeinfo.NewNodeInfo(running)
+	if err := info.SetNode(node); err != nil {
+		t.Fatalf("SetNode() error = %v", err)
+	}
+	unreported := nodeinfo.NewNodeInfo()
+	if err := unreported.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "bare"}}); err != nil {
+		t.Fatalf("SetNode() error = %v", err)
+	}
+	tests := []struct {
+		name        string
+		scratch     string
+		node        *nodeinfo.NodeInfo
+		requireInfo bool
+		want        bool
+	}{
+		{name: "requirement below the unrequested space", scratch: "50", node: info, want: true},
+		{name: "requirement equal to the unrequested space", scratch: "60", node: info, want: true},
+		{name: "requirement above the unrequested space", scratch: "70", node: info, want: false},
+		{name: "no requirement", node: info, want: true},
+		{name: "node without ephemeral storage passes by default", scratch: "10", node: unreported, want: true},
+		{name: "node without ephemeral storage fails when info is required", scratch: "10", node: unreported, requireInfo: true, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{}
+			if tt.scratch != "" {
+				annotations[ScratchAnnotation] = tt.scratch
+			}
+			if got, _ := NewScratchPredicate(tt.requireInfo).Check(testPod(nil, annotations), tt.node, testScv("node")); got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthyRemainderPredicate(t *testing.T) {
+	unhealthy := testCard(8000, 8000)
+	unhealthy.Health = "Unhealthy"
+	tests := []struct {
+		name      string
+		remainder string
+		scv       *scv.Scv
+		want      bool
+	}{
+		{name: "no requirement", scv: testScv("small", testCard(8000, 8000), testCard(8000, 8000)), want: true},
+		{name: "larger node keeps enough healthy cards", remainder: "2", scv: testScv("large", testCard(8000, 8000), testCard(8000, 8000), testCard(8000, 8000), testCard(8000, 8000)), want: true},
+		{name: "exactly the remainder left", remainder: "2", scv: testScv("exact", testCard(8000, 8000), testCard(8000, 8000), testCard(8000, 8000)), want: true},
+		{name: "near-full node leaves too few", remainder: "2", scv: testScv("small", testCard(8000, 8000), testCard(8000, 8000)), want: false},
+		{name: "unhealthy cards do not count", remainder: "2", scv: testScv("degraded", testCard(8000, 8000), testCard(8000, 8000), unhealthy), want: false},
+	}
+	p := NewHealthyRemainderPredicate()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{}
+			if tt.remainder != "" {
+				annotations[HealthyRemainderAnnotation] = tt.remainder
+			}
+			pod := testPod(map[string]string{"scv/number": "1"}, annotations)
+			if got, _ := p.Check(pod, nil, tt.scv); got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCpuRatioPredicate(t *testing.T) {
+	node := func(cpu string) *nodeinfo.NodeInfo {
+		info := nodeinfo.NewNodeInfo()
+		if err := info.SetNode(&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node"},
+			Status:     v1.NodeStatus{Allocatable: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return info
+	}
+	idle := testScv("node", testCard(8000, 8000), testCard(8000, 8000))
+	tests := []struct {
+		name      string
+		cpuPerGpu string
+		node      *nodeinfo.NodeInfo
+		scv       *scv.Scv
+		want      bool
+	}{
+		{name: "CPU-rich node", cpuPerGpu: "8", node: node("32"), scv: idle, want: true},
+		{name: "CPU-starved node", cpuPerGpu: "8", node: node("4"), scv: idle, want: false},
+		{name: "no ratio requirement", node: node("4"), scv: idle, want: true},
+		{name: "node without an idle card reports no ratio", cpuPerGpu: "8", node: node("4"), scv: testScv("node", testCard(4000, 8000)), want: true},
+	}
+	p := NewCpuRatioPredicate()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{}
+			if tt.cpuPerGpu != "" {
+				annotations[CpuPerGpuAnnotation] = tt.cpuPerGpu
+			}
+			if got, _ := p.Check(testPod(nil, annotations), tt.node, tt.scv); got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttestationPredicate(t *testing.T) {
+	node := func(state string) *nodeinfo.NodeInfo {
+		info := nodeinfo.NewNodeInfo()
+		n := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+		if state != "" {
+			n.Annotations = map[string]string{AttestationAnnotation: state}
+		}
+		if err := info.SetNode(n); err != nil {
+			t.Fatal(err)
+		}
+		return info
+	}
+	require := map[string]string{RequireAttestationAnnotation: "true"}
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		node        *nodeinfo.NodeInfo
+		want        bool
+	}{
+		{name: "attested node passes", annotations: require, node: node(AttestationVerified), want: true},
+		{name: "failed attestation is rejected", annotations: require, node: node("failed"), want: false},
+		{name: "node without attestation is rejected", annotations: require, node: node(""), want: false},
+		{name: "normal pod on a node without attestation", node: node(""), want: true},
+	}
+	p := NewAttestationPredicate()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := p.Check(testPod(nil, tt.annotations), tt.node, testScv("node"))
+			if got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+			if !got && reason == "" {
+				t.Error("Check() rejected the node without a reason")
+			}
+		})
+	}
+}
+
+func TestPodRequirementFallThrough(t *testing.T) {
+	defer ResetRequirementParsers()
+	if err := SetRequirementParsers([]string{LabelParser, AnnotationParser, ResourceLimitParser}); err != nil {
+		t.Fatal(err)
+	}
+	limits := testPod(nil, nil)
+	limits.Spec.Containers = []v1.Container{gpuContainer("2", "8192")}
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+		want Requirement
+	}{
+		{name: "label wins over later parsers", pod: testPod(map[string]string{"scv/memory": "1000"}, map[string]string{MemoryAnnotation: "2000"}), want: Requirement{Memory: "1000"}},
+		{name: "falls through to the annotation", pod: testPod(nil, map[string]string{MemoryAnnotation: "2000"}), want: Requirement{Memory: "2000"}},
+		{name: "falls through to resource limits", pod: limits, want: Requirement{Number: "2", Memory: "4096"}},
+		{name: "no parser yields a requirement", pod: testPod(nil, nil), want: Requirement{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PodRequirement(tt.pod); got != tt.want {
+				t.Errorf("PodRequirement() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+type staticParser struct {
+	name string
+	r    Requirement
+}
+
+func (p staticParser) Name() string { return p.name }
+
+func (p staticParser) Parse(pod *v1.Pod) (Requirement, bool) { return p.r, true }
+
+func TestRegisterRequirementParser(t *testing.T) {
+	defer ResetRequirementParsers()
+	pod := testPod(map[string]string{"scv/memory": "1000"}, nil)
+	RegisterRequirementParser(staticParser{name: "custom", r: Requirement{Number: "3"}})
+	if err := SetRequirementParsers([]string{"custom", LabelParser}); err != nil {
+		t.Fatalf("SetRequirementParsers() error = %v", err)
+	}
+	if got := PodRequirement(pod); got != (Requirement{Number: "3"}) {
+		t.Errorf("PodRequirement() = %+v, want the custom parser's requirement", got)
+	}
+	// Registering under the same name replaces the parser in later chains.
+	RegisterRequirementParser(staticParser{name: "custom", r: Requirement{Number: "5"}})
+	if err := SetRequirementParsers([]string{"custom"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := PodRequirement(pod); got != (Requirement{Number: "5"}) {
+		t.Errorf("PodRequirement() = %+v, want the replacement parser's requirement", got)
+	}
+	if err := SetRequirementParsers([]string{"missing"}); err == nil {
+		t.Error("SetRequirementParsers() with an unknown parser succeeded, want an error")
+	}
+	if got := PodRequirement(pod); got != (Requirement{Number: "5"}) {
+		t.Errorf("PodRequirement() after a failed change = %+v, want the previous chain kept", got)
+	}
+	ResetRequirementParsers()
+	if got := PodRequirement(pod); got != (Requirement{Memory: "1000"}) {
+		t.Errorf("PodRequirement() after reset = %+v, want the default label chain", got)
+	}
+	if err := SetRequirementParsers([]string{"custom"}); err == nil {
+		t.Error("SetRequirementParsers() found the custom parser after reset, want an error")
+	}
+}
+
+func TestAgentVersionPredicate(t *testing.T) {
+	versioned := func(v string) *scv.Scv {
+		s := testScv("node", testCard(8000, 8000))
+		if v != "" {
+			s.Annotations = map[string]string{AgentVersionAnnotation: v}
+		}
+		return s
+	}
+	tests := []struct {
+		name    string
+		version string
+		want    bool
+	}{
+		{name: "up-to-date agent", version: "1.2.0", want: true},
+		{name: "newer agent", version: "1.10.1", want: true},
+		{name: "outdated agent", version: "1.1.9", want: false},
+		{name: "missing version", want: false},
+		{name: "unreadable version", version: "latest", want: false},
+	}
+	p := NewAgentVersionPredicate(version.MustParseSemantic("1.2.0"))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := p.Check(testPod(nil, nil), nil, versioned(tt.version)); got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEccReservedMemory(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Annotations: map[string]string{CardEccReservedAnnotation: "1000"}}}
+	tests := []struct {
+		name   string
+		memory string
+		want   bool
+	}{
+		{name: "request within usable memory", memory: "14000", want: true},
+		{name: "request fitting only the raw total", memory: "15500", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testScv("node", testCard(16000, 16000))
+			if fits, _ := PodFitsMemory(1, testPod(map[string]string{"scv/memory": tt.memory}, nil), s); !fits {
+				t.Fatalf("PodFitsMemory() before ECC = false, want true")
+			}
+			ApplyEccReserved(s, node)
+			if got := s.Status.CardList[0].TotalMemory; got != 15000 {
+				t.Errorf("usable memory = %v, want 15000", got)
+			}
+			if fits, _ := PodFitsMemory(1, testPod(map[string]string{"scv/memory": tt.memory}, nil), s); fits != tt.want {
+				t.Errorf("PodFitsMemory() = %v, want %v", fits, tt.want)
+			}
+		})
+	}
+}
+
+func TestSharedCardGapPredicate(t *testing.T) {
+	l := ledger.New()
+	l.Reserve("tenant", ledger.Reservation{Node: "reserved", Cards: []int{0}, Memory: 2000})
+	tests := []struct {
+		name   string
+		memory string
+		scv    *scv.Scv
+		want   bool
+	}{
+		{name: "request leaving the gap on a shared card", memory: "7000", scv: testScv("used", testCard(8000, 12000)), want: true},
+		{name: "request consuming the gap on a shared card", memory: "7500", scv: testScv("used", testCard(8000, 12000)), want: false},
+		{name: "card reserved by a co-tenant keeps the gap", memory: "9500", scv: testScv("reserved", testCard(12000, 12000)), want: false},
+		{name: "idle card needs no gap", memory: "8000", scv: testScv("idle", testCard(8000, 8000)), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := testPod(map[string]string{"scv/memory": tt.memory}, nil)
+			if _, _, ok := SelectCards(pod, tt.scv, l.ReservedCardMemory(tt.scv.GetName(), pod.GetUID())); !ok {
+				t.Fatal("request does not fit without a gap")
+			}
+			if got, _ := NewSharedCardGapPredicate(l, 1000).Check(pod, nil, tt.scv); got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClockLockingPredicate(t *testing.T) {
+	node := func(annotations map[string]string) *nodeinfo.NodeInfo {
+		info := nodeinfo.NewNodeInfo()
+		if err := info.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Annotations: annotations}}); err != nil {
+			t.Fatal(err)
+		}
+		return info
+	}
+	supported := node(map[string]string{ClockLockingAnnotation: ClockLockingSupported})
+	unsupported := node(nil)
+	tests := []struct {
+		name string
+		lock bool
+		node *nodeinfo.NodeInfo
+		want bool
+	}{
+		{name: "locking pod on a supporting node", lock: true, node: supported, want: true},
+		{name: "locking pod on an unsupported node", lock: true, node: unsupported, want: false},
+		{name: "other pod on an unsupported node", node: unsupported, want: true},
+	}
+	p := NewClockLockingPredicate()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{}
+			if tt.lock {
+				annotations[LockClocksAnnotation] = "true"
+			}
+			if got, _ := p.Check(testPod(nil, annotations), tt.node, testScv("node", testCard(8000, 8000))); got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSysmemPredicate(t *testing.T) {
+	node := func(memory string) *nodeinfo.NodeInfo {
+		info := nodeinfo.NewNodeInfo()
+		if err := info.SetNode(&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node"},
+			Status:     v1.NodeStatus{Allocatable: v1.ResourceList{v1.ResourceMemory: resource.MustParse(memory)}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return info
+	}
+	tests := []struct {
+		name   string
+		sysmem string
+		node   *nodeinfo.NodeInfo
+		want   bool
+	}{
+		{name: "host-memory-rich node", sysmem: "32", node: node("128Gi"), want: true},
+		{name: "host-memory-starved node", sysmem: "32", node: node("48Gi"), want: false},
+		{name: "no ratio requirement", node: node("48Gi"), want: true},
+	}
+	p := NewSysmemPredicate()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{}
+			if tt.sysmem != "" {
+				annotations[SysmemPerGpuAnnotation] = tt.sysmem
+			}
+			pod := testPod(map[string]string{"scv/number": "2"}, annotations)
+			if got, _ := p.Check(pod, tt.node, testScv("node", testCard(8000, 8000), testCard(8000, 8000))); got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
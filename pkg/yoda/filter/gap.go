@@ -0,0 +1,37 @@
+package filter
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/ledger"
+)
+
+// CardShared reports whether another pod already uses the card.
+func CardShared(card scv.Card, reserved uint64) bool {
+	return reserved > 0 || card.FreeMemory < card.TotalMemory
+}
+
+// SharedCardGap returns a copy of reserved that also holds gap MB on every
+// shared card, kept as headroom between the pod and its co-tenants.
+func SharedCardGap(s *scv.Scv, reserved map[int]uint64, gap uint64) map[int]uint64 {
+	withGap := map[int]uint64{}
+	for i, memory := range reserved {
+		withGap[i] = memory
+	}
+	for i, card := range s.Status.CardList {
+		if CardShared(card, reserved[i]) {
+			withGap[i] += gap
+		}
+	}
+	return withGap
+}
+
+func NewSharedCardGapPredicate(l *ledger.Ledger, gap uint64) Predicate {
+	return NewPredicate("shared-card-gap", func(pod *v1.Pod, node *nodeinfo.NodeInfo, s *scv.Scv) (bool, string) {
+		_, _, ok := SelectCards(pod, s, SharedCardGap(s, l.ReservedCardMemory(s.GetName(), pod.GetUID()), gap))
+		return ok, "GPU cards cannot keep the memory gap to their co-tenants"
+	})
+}
@@ -0,0 +1,35 @@
+package filter
+
+import (
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// JobSignatureAnnotation overrides the signature derived from the pod's owner and images.
+const JobSignatureAnnotation = "yoda.gpu/job-signature"
+
+// JobSignature identifies recurring runs of the same job: the pod's namespace,
+// controlling owner and container images. Pods without an owner have none.
+func JobSignature(pod *v1.Pod) string {
+	if signature, ok := pod.GetAnnotations()[JobSignatureAnnotation]; ok {
+		return signature
+	}
+	var owner string
+	for _, ref := range pod.GetOwnerReferences() {
+		if ref.Controller != nil && *ref.Controller {
+			owner = ref.Kind + "/" + ref.Name
+			break
+		}
+	}
+	if owner == "" {
+		return ""
+	}
+	images := make([]string, 0, len(pod.Spec.Containers))
+	for _, c := range pod.Spec.Containers {
+		images = append(images, c.Image)
+	}
+	sort.Strings(images)
+	return pod.GetNamespace() + "/" + owner + "@" + strings.Join(images, ",")
+}
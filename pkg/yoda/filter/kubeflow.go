@@ -0,0 +1,66 @@
+package filter
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	KubeflowParser = "kubeflow"
+
+	kubeflowLabelPrefix = "training.kubeflow.org/"
+	// Labels set by older operator releases.
+	kubeflowGroupLabel   = "group-name"
+	kubeflowGroup        = "kubeflow.org"
+	legacyMPIRoleLabel   = "mpi-job-role"
+	kubeflowJobRoleLabel = "training.kubeflow.org/job-role"
+
+	mpiLauncherRole = "launcher"
+)
+
+// kubeflowParser translates pods created by the Kubeflow training operators.
+// MPIJob launchers only run mpirun and need no GPU; replicas of PyTorchJob,
+// TFJob and MPIJob workers request GPUs through container limits.
+type kubeflowParser struct {
+	limits RequirementParser
+}
+
+// NewKubeflowParser reads Kubeflow job pods, treating init containers by the given policy.
+func NewKubeflowParser(initPolicy string) RequirementParser {
+	return kubeflowParser{limits: NewResourceLimitParser(initPolicy)}
+}
+
+func (kubeflowParser) Name() string { return KubeflowParser }
+
+func (p kubeflowParser) Parse(pod *v1.Pod) (Requirement, bool) {
+	labels := pod.GetLabels()
+	if !IsKubeflowPod(pod) {
+		return Requirement{}, false
+	}
+	role := labels[kubeflowJobRoleLabel]
+	if role == "" {
+		role = labels[legacyMPIRoleLabel]
+	}
+	if strings.EqualFold(role, mpiLauncherRole) {
+		return Requirement{Number: "0"}, true
+	}
+	return p.limits.Parse(pod)
+}
+
+// IsKubeflowPod reports whether a Kubeflow training operator created the pod.
+func IsKubeflowPod(pod *v1.Pod) bool {
+	labels := pod.GetLabels()
+	if labels[kubeflowGroupLabel] == kubeflowGroup {
+		return true
+	}
+	if _, ok := labels[legacyMPIRoleLabel]; ok {
+		return true
+	}
+	for key := range labels {
+		if strings.HasPrefix(key, kubeflowLabelPrefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package filter
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+var scaleDownTaints = []string{
+	"ToBeDeletedByClusterAutoscaler",
+	"DeletionCandidateOfClusterAutoscaler",
+}
+
+// NodeMarkedForScaleDown reports whether the cluster autoscaler intends to remove the node.
+func NodeMarkedForScaleDown(node *v1.Node) bool {
+	if node == nil {
+		return false
+	}
+	for _, taint := range node.Spec.Taints {
+		for _, key := range scaleDownTaints {
+			if taint.Key == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DrainAnnotation marks a node an operator is draining.
+const DrainAnnotation = "yoda.gpu/draining"
+
+const (
+	RespectDrainPenalize = "penalize"
+	RespectDrainExclude  = "exclude"
+)
+
+// NodeDraining reports whether the node is cordoned or annotated as being drained.
+func NodeDraining(node *v1.Node) bool {
+	if node == nil {
+		return false
+	}
+	return node.Spec.Unschedulable || strings.EqualFold(node.GetAnnotations()[DrainAnnotation], "true")
+}
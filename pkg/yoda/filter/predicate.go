@@ -0,0 +1,155 @@
+package filter
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+)
+
+type Predicate interface {
+	Name() string
+	Check(pod *v1.Pod, node *nodeinfo.NodeInfo, scv *scv.Scv) (bool, string)
+}
+
+type CheckFunc func(pod *v1.Pod, node *nodeinfo.NodeInfo, scv *scv.Scv) (bool, string)
+
+type predicate struct {
+	name  string
+	check CheckFunc
+}
+
+func NewPredicate(name string, check CheckFunc) Predicate {
+	return &predicate{name: name, check: check}
+}
+
+func (p *predicate) Name() string {
+	return p.name
+}
+
+func (p *predicate) Check(pod *v1.Pod, node *nodeinfo.NodeInfo, scv *scv.Scv) (bool, string) {
+	return p.check(pod, node, scv)
+}
+
+type Observer func(predicate string, elapsed time.Duration)
+
+type observedPredicate struct {
+	Predicate
+	observe Observer
+}
+
+// WithObserver reports how long each Check of the predicate takes.
+func WithObserver(p Predicate, observe Observer) Predicate {
+	return &observedPredicate{Predicate: p, observe: observe}
+}
+
+func (p *observedPredicate) Check(pod *v1.Pod, node *nodeinfo.NodeInfo, scv *scv.Scv) (bool, string) {
+	start := time.Now()
+	defer func() {
+		p.observe(p.Name(), time.Since(start))
+	}()
+	return p.Predicate.Check(pod, node, scv)
+}
+
+const (
+	SeverityReject = "reject"
+	SeverityWarn   = "warn"
+)
+
+// WarnFunc is told about failures of a warn-severity predicate.
+type WarnFunc func(predicate, node, reason string)
+
+type warningPredicate struct {
+	Predicate
+	warn WarnFunc
+}
+
+// WithWarning turns failures of the predicate into warnings that keep the node.
+func WithWarning(p Predicate, warn WarnFunc) Predicate {
+	return &warningPredicate{Predicate: p, warn: warn}
+}
+
+func (p *warningPredicate) Check(pod *v1.Pod, node *nodeinfo.NodeInfo, scv *scv.Scv) (bool, string) {
+	if ok, reason := p.Predicate.Check(pod, node, scv); !ok {
+		p.warn(p.Name(), node.Node().GetName(), reason)
+	}
+	return true, ""
+}
+
+func DefaultPredicates() []Predicate {
+	return []Predicate{
+		NewPredicate("number", func(pod *v1.Pod, node *nodeinfo.NodeInfo, s *scv.Scv) (bool, string) {
+			ok, _ := PodFitsNumber(pod, s)
+			return ok, "insufficient GPU cards"
+		}),
+		NewPredicate("memory", func(pod *v1.Pod, node *nodeinfo.NodeInfo, s *scv.Scv) (bool, string) {
+			_, number := PodFitsNumber(pod, s)
+			ok, _ := PodFitsMemory(number, pod, s)
+			return ok, "insufficient GPU memory"
+		}),
+		NewPredicate("clock", func(pod *v1.Pod, node *nodeinfo.NodeInfo, s *scv.Scv) (bool, string) {
+			_, number := PodFitsNumber(pod, s)
+			ok, _ := PodFitsClock(number, pod, s)
+			return ok, "no GPU card matches the requested clock"
+		}),
+		NewHealthyRemainderPredicate(),
+		NewAttestationPredicate(),
+		NewClockLockingPredicate(),
+	}
+}
+
+// NewGpuIsolationPredicate keeps pods that request no GPU off GPU nodes.
+func NewGpuIsolationPredicate() Predicate {
+	return NewPredicate("gpu-isolation", func(pod *v1.Pod, node *nodeinfo.NodeInfo, s *scv.Scv) (bool, string) {
+		return !PodRequestsNoGpu(pod) || s.Status.CardNumber == 0, "GPU node is reserved for GPU workloads"
+	})
+}
+
+func NewHealthyRemainderPredicate() Predicate {
+	return NewPredicate("healthy-remainder", func(pod *v1.Pod, node *nodeinfo.NodeInfo, s *scv.Scv) (bool, string) {
+		return PodFitsHealthyRemainder(pod, s), "placement would leave too few other healthy cards"
+	})
+}
+
+func NewAttestationPredicate() Predicate {
+	return NewPredicate("attestation", func(pod *v1.Pod, node *nodeinfo.NodeInfo, s *scv.Scv) (bool, string) {
+		return !PodAnnotationIsTrue(pod, RequireAttestationAnnotation) || NodeAttested(node.Node()), "node has no verified attestation"
+	})
+}
+
+func NewClockLockingPredicate() Predicate {
+	return NewPredicate("clock-locking", func(pod *v1.Pod, node *nodeinfo.NodeInfo, s *scv.Scv) (bool, string) {
+		return !PodAnnotationIsTrue(pod, LockClocksAnnotation) || NodeSupportsClockLocking(node.Node()), "node does not permit locking GPU clocks"
+	})
+}
+
+func NewScratchPredicate(requireInfo bool) Predicate {
+	return NewPredicate("scratch", func(pod *v1.Pod, node *nodeinfo.NodeInfo, s *scv.Scv) (bool, string) {
+		return PodFitsScratch(pod, node, requireInfo)
+	})
+}
+
+func NewScaleDownPredicate() Predicate {
+	return NewPredicate("scale-down", func(pod *v1.Pod, node *nodeinfo.NodeInfo, s *scv.Scv) (bool, string) {
+		return !NodeMarkedForScaleDown(node.Node()), "node is marked for scale-down"
+	})
+}
+
+func NewDrainPredicate() Predicate {
+	return NewPredicate("drain", func(pod *v1.Pod, node *nodeinfo.NodeInfo, s *scv.Scv) (bool, string) {
+		return !NodeDraining(node.Node()), "node is being drained"
+	})
+}
+
+// RunPredicates returns the first predicate rejecting the node and its reason,
+// or nil if every predicate passes.
+func RunPredicates(predicates []Predicate, pod *v1.Pod, node *nodeinfo.NodeInfo, scv *scv.Scv) (Predicate, string) {
+	for _, p := range predicates {
+		if ok, reason := p.Check(pod, node, scv); !ok {
+			return p, reason
+		}
+	}
+	return nil, ""
+}
@@ -0,0 +1,324 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	listers "k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	NumberAnnotation = "yoda.gpu/number"
+	MemoryAnnotation = "yoda.gpu/memory"
+	ClockAnnotation  = "yoda.gpu/clock"
+
+	// RequirementsConfigMapAnnotation names a ConfigMap in the pod's namespace
+	// holding number, memory and clock keys.
+	RequirementsConfigMapAnnotation = "yoda.gpu/requirements-configmap"
+	// ProfileAnnotation names one of the configured requirement profiles.
+	ProfileAnnotation = "yoda.gpu/profile"
+
+	GpuResource       = "nvidia.com/gpu"
+	GpuMemoryResource = "yoda.gpu/memory-mb"
+
+	LabelParser         = "label"
+	AnnotationParser    = "annotation"
+	ResourceLimitParser = "resource-limit"
+	ConfigMapRefParser  = "configmap-ref"
+	ProfileRefParser    = "profile-ref"
+)
+
+// Requirement is a pod's GPU request in the vocabulary of the scv/* labels;
+// empty fields were not specified.
+type Requirement struct {
+	Number string `json:"number,omitempty"`
+	Memory string `json:"memory,omitempty"`
+	Clock  string `json:"clock,omitempty"`
+}
+
+func (r Requirement) empty() bool {
+	return r.Number == "" && r.Memory == "" && r.Clock == ""
+}
+
+// RequirementParser reads a pod's GPU requirement from one source.
+type RequirementParser interface {
+	Name() string
+	Parse(pod *v1.Pod) (Requirement, bool)
+}
+
+var (
+	parsersMu sync.RWMutex
+	parsers   map[string]RequirementParser
+	chain     []RequirementParser
+)
+
+func init() {
+	ResetRequirementParsers()
+}
+
+// ResetRequirementParsers drops every registered parser except the built-in
+// ones and restores the default chain, so a new plugin configuration does not
+// inherit parsers registered for a previous one.
+func ResetRequirementParsers() {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	parsers = map[string]RequirementParser{}
+	for _, p := range []RequirementParser{
+		labelParser{},
+		annotationParser{},
+		resourceLimitParser{initPolicy: InitContainerPeak},
+		NewKubeflowParser(InitContainerPeak),
+	} {
+		parsers[p.Name()] = p
+	}
+	chain = []RequirementParser{labelParser{}}
+}
+
+// RegisterRequirementParser makes a parser available to SetRequirementParsers,
+// replacing any parser of the same name.
+func RegisterRequirementParser(p RequirementParser) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	parsers[p.Name()] = p
+}
+
+// SetRequirementParsers sets the parsers PodRequirement tries, in order.
+func SetRequirementParsers(names []string) error {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	next := make([]RequirementParser, 0, len(names))
+	for _, name := range names {
+		p, ok := parsers[name]
+		if !ok {
+			return fmt.Errorf("unknown requirement parser %q", name)
+		}
+		next = append(next, p)
+	}
+	chain = next
+	return nil
+}
+
+// PodRequirement returns the requirement from the first parser in the chain that yields one.
+func PodRequirement(pod *v1.Pod) Requirement {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	for _, p := range chain {
+		if r, ok := p.Parse(pod); ok {
+			return r
+		}
+	}
+	return Requirement{}
+}
+
+// anyParserYieldsRequirement reports whether a registered parser, in the chain
+// or not, reads a requirement from the pod.
+func anyParserYieldsRequirement(pod *v1.Pod) bool {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	for _, p := range parsers {
+		if _, ok := p.Parse(pod); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func requirementFrom(values map[string]string, number, memory, clock string) (Requirement, bool) {
+	r := Requirement{Number: values[number], Memory: values[memory], Clock: values[clock]}
+	return r, !r.empty()
+}
+
+type labelParser struct{}
+
+func (labelParser) Name() string { return LabelParser }
+
+func (labelParser) Parse(pod *v1.Pod) (Requirement, bool) {
+	return requirementFrom(pod.GetLabels(), "scv/number", "scv/memory", "scv/clock")
+}
+
+type annotationParser struct{}
+
+func (annotationParser) Name() string { return AnnotationParser }
+
+func (annotationParser) Parse(pod *v1.Pod) (Requirement, bool) {
+	return requirementFrom(pod.GetAnnotations(), NumberAnnotation, MemoryAnnotation, ClockAnnotation)
+}
+
+const (
+	// InitContainerPeak fits the larger of the init and main container phases,
+	// since init containers run one at a time before the main ones.
+	InitContainerPeak = "peak"
+	// InitContainerSum adds init container limits to the main containers'.
+	InitContainerSum = "sum"
+)
+
+// resourceLimitParser reads the GPU count and total GPU memory from container limits.
+type resourceLimitParser struct {
+	initPolicy string
+}
+
+// NewResourceLimitParser reads container limits, treating init containers by the given policy.
+func NewResourceLimitParser(initPolicy string) RequirementParser {
+	return resourceLimitParser{initPolicy: initPolicy}
+}
+
+func (resourceLimitParser) Name() string { return ResourceLimitParser }
+
+// containerGpuLimits returns the container's GPU count and the memory it
+// needs on each of those cards, rounded up.
+func containerGpuLimits(c v1.Container) (int64, int64) {
+	var number, memory int64
+	if q, ok := c.Resources.Limits[GpuResource]; ok {
+		number = q.Value()
+	}
+	if q, ok := c.Resources.Limits[GpuMemoryResource]; ok {
+		memory = q.Value()
+	}
+	if number > 1 {
+		memory = (memory + number - 1) / number
+	}
+	return number, memory
+}
+
+// Parse treats the main containers as one phase that runs together, needing
+// their summed cards with the largest per-card memory among them. Under peak
+// each init container is its own phase and the pod needs the largest card
+// count and per-card memory of any phase; under sum the init cards are added
+// to the main ones.
+func (p resourceLimitParser) Parse(pod *v1.Pod) (Requirement, bool) {
+	var number, memory int64
+	for _, c := range pod.Spec.Containers {
+		n, m := containerGpuLimits(c)
+		number += n
+		memory = max64(memory, m)
+	}
+	for _, c := range pod.Spec.InitContainers {
+		n, m := containerGpuLimits(c)
+		if p.initPolicy == InitContainerSum {
+			number += n
+		} else {
+			number = max64(number, n)
+		}
+		memory = max64(memory, m)
+	}
+	var r Requirement
+	if number > 0 {
+		r.Number = strconv.FormatInt(number, 10)
+	}
+	if memory > 0 {
+		r.Memory = strconv.FormatInt(memory, 10)
+	}
+	return r, !r.empty()
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+type configMapParser struct {
+	lister listers.ConfigMapLister
+}
+
+// NewConfigMapParser reads requirements from the ConfigMap the pod references.
+func NewConfigMapParser(lister listers.ConfigMapLister) RequirementParser {
+	return &configMapParser{lister: lister}
+}
+
+func (p *configMapParser) Name() string { return ConfigMapRefParser }
+
+func (p *configMapParser) Parse(pod *v1.Pod) (Requirement, bool) {
+	name, ok := pod.GetAnnotations()[RequirementsConfigMapAnnotation]
+	if !ok {
+		return Requirement{}, false
+	}
+	cm, err := p.lister.ConfigMaps(pod.GetNamespace()).Get(name)
+	if err != nil {
+		return Requirement{}, false
+	}
+	return requirementFrom(cm.Data, "number", "memory", "clock")
+}
+
+type profileParser struct {
+	profiles map[string]Requirement
+}
+
+// NewProfileParser resolves the profile named by the pod against the configured profiles.
+func NewProfileParser(profiles map[string]Requirement) RequirementParser {
+	return &profileParser{profiles: profiles}
+}
+
+func (p *profileParser) Name() string { return ProfileRefParser }
+
+func (p *profileParser) Parse(pod *v1.Pod) (Requirement, bool) {
+	r, ok := p.profiles[pod.GetAnnotations()[ProfileAnnotation]]
+	return r, ok && !r.empty()
+}
+
+const ImageDefaultParser = "image-default"
+
+// DefaultRequirementParsers is the chain used when none is configured.
+var DefaultRequirementParsers = []string{LabelParser}
+
+// ParseRequirementSpec reads a requirement written as "number=2,memory=16G,clock=1500".
+func ParseRequirementSpec(spec string) (Requirement, error) {
+	var r Requirement
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return r, fmt.Errorf("invalid requirement %q", entry)
+		}
+		value := strings.TrimSpace(parts[1])
+		switch strings.TrimSpace(parts[0]) {
+		case "number":
+			r.Number = value
+		case "memory":
+			r.Memory = value
+		case "clock":
+			r.Clock = value
+		default:
+			return r, fmt.Errorf("unknown requirement %q", parts[0])
+		}
+	}
+	if r.empty() {
+		return r, fmt.Errorf("empty requirement %q", spec)
+	}
+	return r, nil
+}
+
+type imageDefaultParser struct {
+	defaults map[string]Requirement
+}
+
+// NewImageDefaultParser defaults the requirement of pods by the longest
+// matching image prefix of their containers.
+func NewImageDefaultParser(defaults map[string]Requirement) RequirementParser {
+	return &imageDefaultParser{defaults: defaults}
+}
+
+func (p *imageDefaultParser) Name() string { return ImageDefaultParser }
+
+func (p *imageDefaultParser) Parse(pod *v1.Pod) (Requirement, bool) {
+	var (
+		best  string
+		found bool
+		r     Requirement
+	)
+	for _, c := range pod.Spec.Containers {
+		for prefix, d := range p.defaults {
+			if strings.HasPrefix(c.Image, prefix) && (!found || len(prefix) > len(best)) {
+				best, found, r = prefix, true, d
+			}
+		}
+	}
+	return r, found
+}
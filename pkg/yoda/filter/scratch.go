@@ -0,0 +1,33 @@
+package filter
+
+import (
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+const ScratchAnnotation = "yoda.gpu/scratch-gb"
+
+// PodFitsScratch checks the pod's local scratch requirement against the node's
+// unrequested ephemeral storage. Nodes that do not report ephemeral storage
+// pass unless requireInfo is set.
+func PodFitsScratch(pod *v1.Pod, node *nodeinfo.NodeInfo, requireInfo bool) (bool, string) {
+	value, ok := pod.GetAnnotations()[ScratchAnnotation]
+	if !ok {
+		return true, ""
+	}
+	gb, err := strconv.ParseFloat(value, 64)
+	if err != nil || gb <= 0 {
+		return true, ""
+	}
+	if node.Node() == nil {
+		return !requireInfo, "node reports no scratch space"
+	}
+	allocatable, ok := node.Node().Status.Allocatable[v1.ResourceEphemeralStorage]
+	if !ok {
+		return !requireInfo, "node reports no scratch space"
+	}
+	free := allocatable.Value() - node.RequestedResource().EphemeralStorage
+	return float64(free) >= gb*(1<<30), "insufficient local scratch space"
+}
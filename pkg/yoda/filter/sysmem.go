@@ -0,0 +1,50 @@
+package filter
+
+import (
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+)
+
+const (
+	// SysmemPerGpuAnnotation is the host memory in GB the pod needs for each GPU card.
+	SysmemPerGpuAnnotation = "yoda.gpu/sysmem-per-gpu-gb"
+
+	SysmemHard = "hard"
+	SysmemSoft = "soft"
+)
+
+// SysmemShortfall returns the fraction of the host memory the pod needs for
+// its cards that the node's allocatable memory lacks, zero when it has enough.
+func SysmemShortfall(pod *v1.Pod, node *v1.Node, s *scv.Scv) float64 {
+	value, ok := pod.GetAnnotations()[SysmemPerGpuAnnotation]
+	if !ok || node == nil {
+		return 0
+	}
+	gb, err := strconv.ParseFloat(value, 64)
+	if err != nil || gb <= 0 {
+		return 0
+	}
+	_, number := PodFitsNumber(pod, s)
+	required := gb * float64(number) * (1 << 30)
+	if required == 0 {
+		return 0
+	}
+	var allocatable float64
+	if q, ok := node.Status.Allocatable[v1.ResourceMemory]; ok {
+		allocatable = float64(q.Value())
+	}
+	if allocatable >= required {
+		return 0
+	}
+	return (required - allocatable) / required
+}
+
+func NewSysmemPredicate() Predicate {
+	return NewPredicate("sysmem-per-gpu", func(pod *v1.Pod, node *nodeinfo.NodeInfo, s *scv.Scv) (bool, string) {
+		return SysmemShortfall(pod, node.Node(), s) == 0, "insufficient host memory for the requested GPU cards"
+	})
+}
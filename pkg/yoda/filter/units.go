@@ -0,0 +1,51 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Memory quantities are expressed in MB, matching the values reported by SCV.
+var memoryUnits = []struct {
+	suffix string
+	factor float64
+}{
+	{"GIB", 1024},
+	{"GI", 1024},
+	{"GB", 1024},
+	{"G", 1024},
+	{"MIB", 1},
+	{"MI", 1},
+	{"MB", 1},
+	{"M", 1},
+}
+
+func ParseMemory(str string) uint64 {
+	s := strings.ToUpper(strings.TrimSpace(str))
+	factor := float64(1)
+	for _, unit := range memoryUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			factor = unit.factor
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 {
+		return 0
+	}
+	return uint64(f * factor)
+}
+
+// ParseMemoryPercent reads a share of a card such as "50" or "12.5%". Values
+// outside 0-100 are rejected.
+func ParseMemoryPercent(str string) (float64, error) {
+	s := strings.TrimSuffix(strings.TrimSpace(str), "%")
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsNaN(f) || f < 0 || f > 100 {
+		return 0, fmt.Errorf("invalid memory percent %q, want a value between 0 and 100", str)
+	}
+	return f, nil
+}
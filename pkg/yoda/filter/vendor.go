@@ -0,0 +1,33 @@
+package filter
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// VendorLabel names the GPU vendor of a node, pods ask for one with the same annotation.
+	VendorLabel = "yoda.gpu/vendor"
+
+	DefaultVendor = "nvidia"
+)
+
+// NodeVendor returns the GPU vendor of the node, SCV nodes without the label are NVIDIA.
+func NodeVendor(node *v1.Node) string {
+	if vendor := strings.ToLower(strings.TrimSpace(node.GetLabels()[VendorLabel])); vendor != "" {
+		return vendor
+	}
+	return DefaultVendor
+}
+
+// PodVendor returns the GPU vendor the pod requires, by annotation or node selector.
+func PodVendor(pod *v1.Pod) (string, bool) {
+	if vendor, ok := pod.GetAnnotations()[VendorLabel]; ok {
+		return strings.ToLower(strings.TrimSpace(vendor)), true
+	}
+	if vendor, ok := pod.Spec.NodeSelector[VendorLabel]; ok {
+		return strings.ToLower(strings.TrimSpace(vendor)), true
+	}
+	return "", false
+}
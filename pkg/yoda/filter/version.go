@@ -0,0 +1,25 @@
+package filter
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+)
+
+// AgentVersionAnnotation is the semantic version of the SCV agent that wrote the object.
+const AgentVersionAnnotation = "scv/agent-version"
+
+// ScvAgentAtLeast reports whether the SCV was written by an agent of at least
+// the minimum version. SCVs without a readable version fail.
+func ScvAgentAtLeast(s *scv.Scv, min *version.Version) bool {
+	v, err := version.ParseSemantic(s.GetAnnotations()[AgentVersionAnnotation])
+	return err == nil && v.AtLeast(min)
+}
+
+func NewAgentVersionPredicate(min *version.Version) Predicate {
+	return NewPredicate("agent-version", func(pod *v1.Pod, node *nodeinfo.NodeInfo, s *scv.Scv) (bool, string) {
+		return ScvAgentAtLeast(s, min), "SCV agent is older than " + min.String()
+	})
+}
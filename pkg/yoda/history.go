@@ -0,0 +1,31 @@
+package yoda
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// recordSuccessfulPlacements remembers the node of every GPU pod that
+// succeeded, keyed by its job signature.
+func (y *Yoda) recordSuccessfulPlacements() {
+	y.handle.SharedInformerFactory().Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPod, ok := oldObj.(*v1.Pod)
+			if !ok {
+				return
+			}
+			newPod, ok := newObj.(*v1.Pod)
+			if !ok || newPod.Spec.NodeName == "" || filter.PodRequestsNoGpu(newPod) {
+				return
+			}
+			if oldPod.Status.Phase == v1.PodSucceeded || newPod.Status.Phase != v1.PodSucceeded {
+				return
+			}
+			if signature := filter.JobSignature(newPod); signature != "" {
+				y.history.Record(signature, newPod.Spec.NodeName)
+			}
+		},
+	})
+}
@@ -0,0 +1,142 @@
+package yoda
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/metrics"
+)
+
+const (
+	CycleTimingsStateKey = "CycleTimings"
+
+	PhaseFetch   = "fetch"
+	PhaseFilter  = "filter"
+	PhaseCollect = "collect"
+	PhaseScore   = "score"
+
+	sloWarningInterval = time.Minute
+)
+
+// CycleTimings records when Yoda was busy in each phase while scheduling one pod.
+// The framework filters and scores nodes in parallel, so a phase takes the wall
+// clock time its calls span rather than the sum of their durations.
+type CycleTimings struct {
+	mu     sync.Mutex
+	phases map[string][]interval
+}
+
+type interval struct {
+	start, end time.Time
+}
+
+func NewCycleTimings() *CycleTimings {
+	return &CycleTimings{phases: map[string][]interval{}}
+}
+
+func (c *CycleTimings) Clone() framework.StateData {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clone := NewCycleTimings()
+	for phase, intervals := range c.phases {
+		clone.phases[phase] = append([]interval(nil), intervals...)
+	}
+	return clone
+}
+
+func (c *CycleTimings) Add(phase string, start, end time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.phases[phase] = append(c.phases[phase], interval{start: start, end: end})
+}
+
+// Total returns the time spent across all phases and the phase that took longest.
+func (c *CycleTimings) Total() (time.Duration, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var (
+		all      []interval
+		dominant string
+		longest  time.Duration
+	)
+	for phase, intervals := range c.phases {
+		all = append(all, intervals...)
+		if d := span(intervals); dominant == "" || d > longest {
+			dominant, longest = phase, d
+		}
+	}
+	return span(all), dominant
+}
+
+// span returns the time covered by the intervals, counting overlaps once. Calls
+// made in parallel span from the first start to the last end, while the gap
+// between the framework's filter and score stages is left out.
+func span(intervals []interval) time.Duration {
+	sorted := append([]interval(nil), intervals...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].start.Before(sorted[j].start)
+	})
+	var (
+		total   time.Duration
+		current interval
+	)
+	for i, in := range sorted {
+		switch {
+		case i == 0:
+			current = in
+		case !in.start.After(current.end):
+			if in.end.After(current.end) {
+				current.end = in.end
+			}
+		default:
+			total += current.end.Sub(current.start)
+			current = in
+		}
+	}
+	if len(sorted) > 0 {
+		total += current.end.Sub(current.start)
+	}
+	return total
+}
+
+func readCycleTimings(state *framework.CycleState) *CycleTimings {
+	state.RLock()
+	defer state.RUnlock()
+	d, err := state.Read(CycleTimingsStateKey)
+	if err != nil {
+		return nil
+	}
+	timings, _ := d.(*CycleTimings)
+	return timings
+}
+
+func (y *Yoda) observePhase(state *framework.CycleState, phase string, start time.Time) {
+	if timings := readCycleTimings(state); timings != nil {
+		timings.Add(phase, start, y.clock.Now())
+	}
+}
+
+// checkCycleSLO reports pods whose processing exceeded CycleLatencySLOms,
+// warning at most once per sloWarningInterval.
+func (y *Yoda) checkCycleSLO(state *framework.CycleState, pod *v1.Pod) {
+	timings := readCycleTimings(state)
+	if y.args.CycleLatencySLOms <= 0 || timings == nil {
+		return
+	}
+	total, dominant := timings.Total()
+	if total <= time.Duration(y.args.CycleLatencySLOms)*time.Millisecond {
+		return
+	}
+	metrics.CycleSLOBreaches.WithLabelValues(dominant).Inc()
+	y.sloMu.Lock()
+	defer y.sloMu.Unlock()
+	if now := y.clock.Now(); now.Sub(y.lastSLOWarning) >= sloWarningInterval {
+		y.lastSLOWarning = now
+		klog.Warningf("scheduling pod %v took %v in yoda, over the %vms SLO, mostly in %v", pod.Name, total, y.args.CycleLatencySLOms, dominant)
+	}
+}
@@ -0,0 +1,136 @@
+package yoda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/component-base/metrics/legacyregistry"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/metrics"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/scvcache"
+)
+
+func TestCycleTimingsTotal(t *testing.T) {
+	at := func(ms int) time.Time {
+		return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(ms) * time.Millisecond)
+	}
+	c := NewCycleTimings()
+	// Three nodes fetched in parallel, then filtered.
+	c.Add(PhaseFetch, at(0), at(40))
+	c.Add(PhaseFetch, at(5), at(50))
+	c.Add(PhaseFetch, at(10), at(30))
+	c.Add(PhaseFilter, at(50), at(60))
+	// The score stage starts after a gap spent outside yoda.
+	c.Add(PhaseFetch, at(100), at(120))
+	c.Add(PhaseScore, at(120), at(125))
+	total, dominant := c.Total()
+	if want := 85 * time.Millisecond; total != want {
+		t.Errorf("Total() = %v, want %v", total, want)
+	}
+	if dominant != PhaseFetch {
+		t.Errorf("dominant phase = %v, want %v", dominant, PhaseFetch)
+	}
+	clone := c.Clone().(*CycleTimings)
+	c.Add(PhaseScore, at(200), at(400))
+	if total, _ := clone.Total(); total != 85*time.Millisecond {
+		t.Errorf("clone Total() = %v after adding to the original, want 85ms", total)
+	}
+}
+
+// slowClient stands in for an API server that takes delay to return each SCV.
+type slowClient struct {
+	client.Client
+	clock *clock.FakeClock
+	delay time.Duration
+}
+
+func (c *slowClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	c.clock.Step(c.delay)
+	return c.Client.Get(ctx, key, obj)
+}
+
+// counterValue reads the yoda counter with the given label value from the
+// scheduler's metrics registry.
+func counterValue(t *testing.T, name, label, value string) float64 {
+	t.Helper()
+	families, err := legacyregistry.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != metrics.YodaSubsystem+"_"+name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == label && l.GetValue() == value {
+					return m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestSlowScvFetchBreachesSLO(t *testing.T) {
+	metrics.Register()
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod", Labels: map[string]string{"scv/memory": "4000"}}}
+	nodes := []*v1.Node{testNode("a"), testNode("b"), testNode("c")}
+	y := newTestYoda(t, pod, nodes)
+	fake := clock.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	y.clock = fake
+	y.scvs = scvcache.New(&slowClient{
+		Client: testScvClient(t, testScv("a", testCard(8000, 8000)), testScv("b", testCard(8000, 8000)), testScv("c", testCard(8000, 8000))),
+		clock:  fake,
+		delay:  50 * time.Millisecond,
+	}, fake, 0)
+	y.args.CycleLatencySLOms = 100
+
+	ctx := context.Background()
+	state := framework.NewCycleState()
+	if status := y.PreFilter(ctx, state, pod); !status.IsSuccess() {
+		t.Fatalf("PreFilter() = %v", status.Message())
+	}
+	var scores framework.NodeScoreList
+	for _, n := range nodes {
+		nodeInfo, err := y.handle.SnapshotSharedLister().NodeInfos().Get(n.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status := y.Filter(ctx, state, pod, nodeInfo); !status.IsSuccess() {
+			t.Fatalf("Filter(%v) = %v", n.Name, status.Message())
+		}
+	}
+	if status := y.PostFilter(ctx, state, pod, nodes, nil); !status.IsSuccess() {
+		t.Fatalf("PostFilter() = %v", status.Message())
+	}
+	for _, n := range nodes {
+		s, status := y.Score(ctx, state, pod, n.Name)
+		if !status.IsSuccess() {
+			t.Fatalf("Score(%v) = %v", n.Name, status.Message())
+		}
+		scores = append(scores, framework.NodeScore{Name: n.Name, Score: s})
+	}
+
+	total, dominant := readCycleTimings(state).Total()
+	if want := 300 * time.Millisecond; total != want {
+		t.Errorf("cycle took %v in yoda, want %v spent fetching six SCVs", total, want)
+	}
+	if dominant != PhaseFetch {
+		t.Errorf("dominant phase = %v, want %v", dominant, PhaseFetch)
+	}
+	before := counterValue(t, "cycle_slo_breaches_total", "phase", PhaseFetch)
+	if status := y.NormalizeScore(ctx, state, pod, scores); !status.IsSuccess() {
+		t.Fatalf("NormalizeScore() = %v", status.Message())
+	}
+	if got := counterValue(t, "cycle_slo_breaches_total", "phase", PhaseFetch) - before; got != 1 {
+		t.Errorf("fetch SLO breaches grew by %v, want 1", got)
+	}
+}
@@ -0,0 +1,173 @@
+package ledger
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Reservation records the cards Yoda picked for a pod on a node.
+type Reservation struct {
+	Node   string `json:"node"`
+	Cards  []int  `json:"cards"`
+	Memory uint64 `json:"memory"`
+	// Compute is the percentage of each card's compute the pod declared.
+	Compute        uint `json:"compute,omitempty"`
+	BandwidthHeavy bool `json:"bandwidthHeavy,omitempty"`
+	// Class is the pod's workload class, such as interactive or batch.
+	Class string `json:"class,omitempty"`
+	// Mps records that the pod runs as a client of the card's MPS daemon.
+	Mps bool `json:"mps,omitempty"`
+	// Reserved is when the cards were picked.
+	Reserved time.Time `json:"reserved"`
+	// Settled is set once the pod runs and SCV's free memory counts its memory.
+	Settled bool `json:"-"`
+}
+
+// FreedRetention is how long the ledger remembers capacity freed by finished pods.
+const FreedRetention = 30 * time.Minute
+
+// Freed records the cards a finished pod gave back.
+type Freed struct {
+	Reservation
+	At time.Time
+}
+
+// Ledger keeps the reservations made by this scheduler that SCV may not reflect yet.
+type Ledger struct {
+	mu    sync.RWMutex
+	pods  map[types.UID]Reservation
+	freed []Freed
+}
+
+func New() *Ledger {
+	return &Ledger{pods: map[types.UID]Reservation{}}
+}
+
+func (l *Ledger) Reserve(uid types.UID, r Reservation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pods[uid] = r
+}
+
+func (l *Ledger) Release(uid types.UID) (Reservation, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r, ok := l.pods[uid]
+	delete(l.pods, uid)
+	return r, ok
+}
+
+// Settle marks the pod's reservation as reflected in SCV, reporting whether an
+// unsettled reservation was found.
+func (l *Ledger) Settle(uid types.UID) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r, ok := l.pods[uid]
+	if !ok || r.Settled {
+		return false
+	}
+	r.Settled = true
+	l.pods[uid] = r
+	return true
+}
+
+// Free releases the pod's reservation and remembers it as capacity freed at the given time.
+func (l *Ledger) Free(uid types.UID, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r, ok := l.pods[uid]
+	delete(l.pods, uid)
+	kept := l.freed[:0]
+	for _, f := range l.freed {
+		if at.Sub(f.At) < FreedRetention {
+			kept = append(kept, f)
+		}
+	}
+	l.freed = kept
+	if ok {
+		l.freed = append(l.freed, Freed{Reservation: r, At: at})
+	}
+}
+
+// RecentlyFreed returns what finished pods freed on the node since the given time.
+func (l *Ledger) RecentlyFreed(node string, since time.Time) []Freed {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	var freed []Freed
+	for _, f := range l.freed {
+		if f.Node == node && f.At.After(since) {
+			freed = append(freed, f)
+		}
+	}
+	return freed
+}
+
+func (l *Ledger) Get(uid types.UID) (Reservation, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	r, ok := l.pods[uid]
+	return r, ok
+}
+
+func (l *Ledger) Node(name string) map[types.UID]Reservation {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	reservations := map[types.UID]Reservation{}
+	for uid, r := range l.pods {
+		if r.Node == name {
+			reservations[uid] = r
+		}
+	}
+	return reservations
+}
+
+// ReservedMemory sums the memory reserved on the node that SCV does not count yet.
+func (l *Ledger) ReservedMemory(node string) uint64 {
+	var reserved uint64
+	for _, r := range l.Node(node) {
+		if r.Settled {
+			continue
+		}
+		reserved += r.Memory * uint64(len(r.Cards))
+	}
+	return reserved
+}
+
+// ReservedCompute sums the declared compute percentage reserved on each card of the node.
+func (l *Ledger) ReservedCompute(node string) map[int]uint {
+	compute := map[int]uint{}
+	for _, r := range l.Node(node) {
+		for _, card := range r.Cards {
+			compute[card] += r.Compute
+		}
+	}
+	return compute
+}
+
+// ReservedCardMemory sums the memory reserved on each card of the node that SCV
+// does not count yet, leaving out one pod.
+func (l *Ledger) ReservedCardMemory(node string, except types.UID) map[int]uint64 {
+	memory := map[int]uint64{}
+	for uid, r := range l.Node(node) {
+		if uid == except || r.Settled {
+			continue
+		}
+		for _, card := range r.Cards {
+			memory[card] += r.Memory
+		}
+	}
+	return memory
+}
+
+// CardReservations groups the reservations on the node by card.
+func (l *Ledger) CardReservations(node string) map[int][]Reservation {
+	cards := map[int][]Reservation{}
+	for _, r := range l.Node(node) {
+		for _, card := range r.Cards {
+			cards[card] = append(cards[card], r)
+		}
+	}
+	return cards
+}
@@ -0,0 +1,90 @@
+package ledger
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestReservedCardMemory(t *testing.T) {
+	tests := []struct {
+		name    string
+		pods    map[types.UID]Reservation
+		settle  []types.UID
+		except  types.UID
+		want    map[int]uint64
+		wantSum uint64
+	}{
+		{
+			name: "sums pods sharing a card",
+			pods: map[types.UID]Reservation{
+				"a": {Node: "node", Cards: []int{0, 1}, Memory: 1000},
+				"b": {Node: "node", Cards: []int{1}, Memory: 500},
+			},
+			want:    map[int]uint64{0: 1000, 1: 1500},
+			wantSum: 2500,
+		},
+		{
+			name: "leaves out the excepted pod",
+			pods: map[types.UID]Reservation{
+				"a": {Node: "node", Cards: []int{0}, Memory: 1000},
+				"b": {Node: "node", Cards: []int{0}, Memory: 500},
+			},
+			except:  "a",
+			want:    map[int]uint64{0: 500},
+			wantSum: 1500,
+		},
+		{
+			name: "settled pods are already counted by SCV",
+			pods: map[types.UID]Reservation{
+				"a": {Node: "node", Cards: []int{0}, Memory: 1000},
+				"b": {Node: "node", Cards: []int{0}, Memory: 500},
+			},
+			settle:  []types.UID{"a"},
+			want:    map[int]uint64{0: 500},
+			wantSum: 500,
+		},
+		{
+			name: "other nodes are ignored",
+			pods: map[types.UID]Reservation{
+				"a": {Node: "other", Cards: []int{0}, Memory: 1000},
+			},
+			want:    map[int]uint64{},
+			wantSum: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New()
+			for uid, r := range tt.pods {
+				l.Reserve(uid, r)
+			}
+			for _, uid := range tt.settle {
+				if !l.Settle(uid) {
+					t.Fatalf("Settle(%v) found no reservation", uid)
+				}
+			}
+			if got := l.ReservedCardMemory("node", tt.except); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReservedCardMemory() = %v, want %v", got, tt.want)
+			}
+			if got := l.ReservedMemory("node"); got != tt.wantSum {
+				t.Errorf("ReservedMemory() = %v, want %v", got, tt.wantSum)
+			}
+		})
+	}
+}
+
+func TestSettleKeepsTenants(t *testing.T) {
+	l := New()
+	l.Reserve("a", Reservation{Node: "node", Cards: []int{0}, Memory: 1000, BandwidthHeavy: true})
+	if !l.Settle("a") {
+		t.Fatal("Settle() found no reservation")
+	}
+	if l.Settle("a") {
+		t.Error("Settle() settled the reservation twice")
+	}
+	if got := len(l.CardReservations("node")[0]); got != 1 {
+		t.Errorf("CardReservations() has %v tenants on card 0, want 1", got)
+	}
+}
@@ -0,0 +1,77 @@
+package ledger
+
+import (
+	"encoding/json"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+)
+
+// ReservationsAnnotation holds the reservations on an SCV object as a JSON map
+// from pod UID to Reservation, so the device plugin allocates the cards the
+// scheduler picked. The SCV status schema has no field for them, so they are
+// kept in the metadata. See the readme for the contract with device plugins.
+const ReservationsAnnotation = "yoda.gpu/reservations"
+
+// ScvReservationTTL drops entries whose pod events were missed, for example
+// across a scheduler restart, so they stop holding cards.
+const ScvReservationTTL = 15 * time.Minute
+
+// ScvReservations returns the reservations on the SCV that have not expired by now.
+func ScvReservations(s *scv.Scv, now time.Time) map[types.UID]Reservation {
+	reservations := decodeScvReservations(s)
+	for uid, r := range reservations {
+		if now.Sub(r.Reserved) >= ScvReservationTTL {
+			delete(reservations, uid)
+		}
+	}
+	return reservations
+}
+
+// SetScvReservation records the pod's reservation and drops expired ones.
+func SetScvReservation(s *scv.Scv, uid types.UID, r Reservation) error {
+	reservations := ScvReservations(s, r.Reserved)
+	reservations[uid] = r
+	return writeScvReservations(s, reservations)
+}
+
+func RemoveScvReservation(s *scv.Scv, uid types.UID) (bool, error) {
+	reservations := decodeScvReservations(s)
+	if _, ok := reservations[uid]; !ok {
+		return false, nil
+	}
+	delete(reservations, uid)
+	return true, writeScvReservations(s, reservations)
+}
+
+func decodeScvReservations(s *scv.Scv) map[types.UID]Reservation {
+	reservations := map[types.UID]Reservation{}
+	if data, ok := s.GetAnnotations()[ReservationsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(data), &reservations); err != nil {
+			klog.Errorf("Decode SCV %v Reservations Error: %v", s.GetName(), err)
+		}
+	}
+	return reservations
+}
+
+func writeScvReservations(s *scv.Scv, reservations map[types.UID]Reservation) error {
+	annotations := s.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if len(reservations) == 0 {
+		delete(annotations, ReservationsAnnotation)
+		s.SetAnnotations(annotations)
+		return nil
+	}
+	data, err := json.Marshal(reservations)
+	if err != nil {
+		return err
+	}
+	annotations[ReservationsAnnotation] = string(data)
+	s.SetAnnotations(annotations)
+	return nil
+}
@@ -0,0 +1,84 @@
+package ledger
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+)
+
+func TestScvReservations(t *testing.T) {
+	now := time.Date(2020, 9, 1, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		set    map[types.UID]Reservation
+		remove []types.UID
+		want   []types.UID
+	}{
+		{
+			name: "recorded by pod UID",
+			set: map[types.UID]Reservation{
+				"a": {Node: "node", Cards: []int{0}, Memory: 1000, Reserved: now},
+				"b": {Node: "node", Cards: []int{1}, Memory: 1000, Reserved: now},
+			},
+			want: []types.UID{"a", "b"},
+		},
+		{
+			name: "removed entries are gone",
+			set: map[types.UID]Reservation{
+				"a": {Node: "node", Cards: []int{0}, Memory: 1000, Reserved: now},
+				"b": {Node: "node", Cards: []int{1}, Memory: 1000, Reserved: now},
+			},
+			remove: []types.UID{"a"},
+			want:   []types.UID{"b"},
+		},
+		{
+			name: "expired entries are dropped",
+			set: map[types.UID]Reservation{
+				"a": {Node: "node", Cards: []int{0}, Memory: 1000, Reserved: now.Add(-ScvReservationTTL)},
+				"b": {Node: "node", Cards: []int{1}, Memory: 1000, Reserved: now},
+			},
+			want: []types.UID{"b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &scv.Scv{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+			for uid, r := range tt.set {
+				if err := SetScvReservation(s, uid, r); err != nil {
+					t.Fatalf("SetScvReservation() error = %v", err)
+				}
+			}
+			for _, uid := range tt.remove {
+				if removed, err := RemoveScvReservation(s, uid); err != nil || !removed {
+					t.Fatalf("RemoveScvReservation() = %v, %v", removed, err)
+				}
+			}
+			got := ScvReservations(s, now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ScvReservations() = %v, want %v", got, tt.want)
+			}
+			for _, uid := range tt.want {
+				if _, ok := got[uid]; !ok {
+					t.Errorf("ScvReservations() is missing %v", uid)
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveLastScvReservation(t *testing.T) {
+	s := &scv.Scv{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	if err := SetScvReservation(s, "a", Reservation{Node: "node", Cards: []int{0}, Reserved: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RemoveScvReservation(s, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.GetAnnotations()[ReservationsAnnotation]; ok {
+		t.Error("annotation kept after its last reservation was removed")
+	}
+}
@@ -0,0 +1,44 @@
+package yoda
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// checkPodLimits returns why the pod exceeds the per-pod GPU caps, or "".
+// Memory relative to a node's cards is only known per node and is not capped.
+func (y *Yoda) checkPodLimits(pod *v1.Pod) string {
+	cards := filter.PodCardCount(pod)
+	if y.args.MaxPodGpuCards > 0 && cards > uint(y.args.MaxPodGpuCards) {
+		return fmt.Sprintf("pod requests %v GPU cards, above the maxPodGpuCards cap of %v", cards, y.args.MaxPodGpuCards)
+	}
+	if y.args.MaxPodGpuMemory == "" {
+		return ""
+	}
+	memory, ok := filter.PodMemory(pod, nil)
+	if !ok {
+		return ""
+	}
+	limit := filter.ParseMemory(y.args.MaxPodGpuMemory)
+	if total := memory * uint64(cards); total > limit {
+		return fmt.Sprintf("pod requests %vMB of GPU memory, above the maxPodGpuMemory cap of %v", total, y.args.MaxPodGpuMemory)
+	}
+	return ""
+}
+
+// namespaceAllowed reports whether the pod's namespace may use GPU nodes. An
+// empty allowlist permits every namespace.
+func (y *Yoda) namespaceAllowed(pod *v1.Pod) bool {
+	if len(y.args.AllowedNamespaces) == 0 {
+		return true
+	}
+	for _, ns := range y.args.AllowedNamespaces {
+		if ns == pod.GetNamespace() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package yoda
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"k8s.io/klog"
+)
+
+const MaintenanceReason = "scheduler in maintenance mode"
+
+func (y *Yoda) InMaintenance() bool {
+	return atomic.LoadInt32(&y.maintenance) == 1
+}
+
+// SetMaintenanceMode stops or resumes placing new GPU pods without restarting the scheduler.
+func (y *Yoda) SetMaintenanceMode(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	if atomic.SwapInt32(&y.maintenance, v) != v {
+		klog.Infof("yoda maintenance mode set to %v", enabled)
+	}
+}
+
+func (y *Yoda) handleMaintenance(r *http.Request) (interface{}, error) {
+	if r.Method == http.MethodPut || r.Method == http.MethodPost {
+		enabled, err := strconv.ParseBool(r.URL.Query().Get("enabled"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid enabled parameter: %v", err)
+		}
+		y.SetMaintenanceMode(enabled)
+	}
+	return map[string]bool{"maintenance": y.InMaintenance()}, nil
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const YodaSubsystem = "yoda"
+
+var (
+	PredicateLatency = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      YodaSubsystem,
+			Name:           "predicate_evaluation_duration_seconds",
+			Help:           "Latency of evaluating a filter predicate against a node, by predicate.",
+			Buckets:        metrics.ExponentialBuckets(0.00001, 2, 15),
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"predicate"})
+
+	CycleSLOBreaches = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      YodaSubsystem,
+			Name:           "cycle_slo_breaches_total",
+			Help:           "Number of pods whose processing in yoda exceeded the cycle latency SLO, by dominant phase.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"phase"})
+
+	PredicateWarnings = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      YodaSubsystem,
+			Name:           "predicate_warnings_total",
+			Help:           "Number of warn-severity predicate failures that did not exclude the node, by predicate.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"predicate"})
+
+	metricsList = []metrics.Registerable{
+		PredicateLatency,
+		CycleSLOBreaches,
+		PredicateWarnings,
+	}
+
+	registerMetrics sync.Once
+)
+
+// Register registers the Yoda metrics in the scheduler's legacy registry.
+func Register() {
+	registerMetrics.Do(func() {
+		for _, metric := range metricsList {
+			legacyregistry.MustRegister(metric)
+		}
+	})
+}
+
+func ObservePredicateLatency(predicate string, elapsed time.Duration) {
+	PredicateLatency.WithLabelValues(predicate).Observe(elapsed.Seconds())
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// predicateObservations returns the histogram sample count of each predicate.
+func predicateObservations(t *testing.T) map[string]uint64 {
+	t.Helper()
+	families, err := legacyregistry.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]uint64{}
+	for _, family := range families {
+		if family.GetName() != YodaSubsystem+"_predicate_evaluation_duration_seconds" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "predicate" {
+					counts[label.GetValue()] = m.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	return counts
+}
+
+func TestObservePredicateLatency(t *testing.T) {
+	Register()
+	var predicates []filter.Predicate
+	for _, p := range filter.DefaultPredicates() {
+		predicates = append(predicates, filter.WithObserver(p, ObservePredicateLatency))
+	}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Labels: map[string]string{"scv/memory": "1000"}}}
+	node := nodeinfo.NewNodeInfo()
+	if err := node.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}); err != nil {
+		t.Fatal(err)
+	}
+	fits := &scv.Scv{Status: scv.ScvStatus{CardNumber: 1, FreeMemorySum: 8000, TotalMemorySum: 8000, CardList: scv.CardList{
+		{Health: "Healthy", FreeMemory: 8000, TotalMemory: 8000, Clock: 1500},
+	}}}
+
+	before := predicateObservations(t)
+	for i := 0; i < 3; i++ {
+		if p, reason := filter.RunPredicates(predicates, pod, node, fits); p != nil {
+			t.Fatalf("predicate %v rejected the node: %v", p.Name(), reason)
+		}
+	}
+	after := predicateObservations(t)
+	for _, p := range predicates {
+		if got := after[p.Name()] - before[p.Name()]; got != 3 {
+			t.Errorf("predicate %v recorded %v observations, want 3", p.Name(), got)
+		}
+	}
+
+	// The first rejection stops the pipeline, so later predicates are not observed.
+	before = after
+	full := &scv.Scv{}
+	if p, _ := filter.RunPredicates(predicates, pod, node, full); p == nil || p.Name() != "number" {
+		t.Fatalf("RunPredicates() rejected by %v, want number", p)
+	}
+	after = predicateObservations(t)
+	for _, p := range predicates {
+		want := uint64(0)
+		if p.Name() == "number" {
+			want = 1
+		}
+		if got := after[p.Name()] - before[p.Name()]; got != want {
+			t.Errorf("predicate %v recorded %v observations after a rejection, want %v", p.Name(), got, want)
+		}
+	}
+}
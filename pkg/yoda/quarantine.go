@@ -0,0 +1,71 @@
+package yoda
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/stats"
+)
+
+const (
+	OOMKilledReason  = "OOMKilled"
+	QuarantineReason = "quarantined after repeated GPU OOM kills"
+)
+
+func (y *Yoda) Quarantined(nodeName string) bool {
+	return y.quarantine != nil && y.quarantine.Quarantined(nodeName)
+}
+
+// watchGpuOOMs counts OOM-killed containers of GPU pods per node and
+// quarantines nodes that exceed the configured threshold.
+func (y *Yoda) watchGpuOOMs() {
+	y.quarantine = stats.NewQuarantine(y.clock,
+		time.Duration(y.args.OomQuarantineWindowMinutes)*time.Minute,
+		uint64(y.args.OomQuarantineThreshold),
+		time.Duration(y.args.OomQuarantineCooldownMinutes)*time.Minute)
+	y.handle.SharedInformerFactory().Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPod, ok := oldObj.(*v1.Pod)
+			if !ok {
+				return
+			}
+			newPod, ok := newObj.(*v1.Pod)
+			if !ok || newPod.Spec.NodeName == "" || filter.PodRequestsNoGpu(newPod) {
+				return
+			}
+			for i := 0; i < newOOMKills(oldPod, newPod); i++ {
+				if y.quarantine.Record(newPod.Spec.NodeName) {
+					klog.Warningf("node %v %v", newPod.Spec.NodeName, QuarantineReason)
+				}
+			}
+		},
+	})
+}
+
+// newOOMKills counts containers that were OOM-killed between two versions of a pod.
+func newOOMKills(oldPod, newPod *v1.Pod) int {
+	previous := map[string]v1.ContainerStatus{}
+	for _, status := range oldPod.Status.ContainerStatuses {
+		previous[status.Name] = status
+	}
+	kills := 0
+	for _, status := range newPod.Status.ContainerStatuses {
+		old := previous[status.Name]
+		if terminatedByOOM(status.State) && !terminatedByOOM(old.State) {
+			kills++
+			continue
+		}
+		if status.RestartCount > old.RestartCount && !terminatedByOOM(old.State) && terminatedByOOM(status.LastTerminationState) {
+			kills++
+		}
+	}
+	return kills
+}
+
+func terminatedByOOM(state v1.ContainerState) bool {
+	return state.Terminated != nil && state.Terminated.Reason == OOMKilledReason
+}
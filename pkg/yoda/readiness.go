@@ -0,0 +1,82 @@
+package yoda
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/admin"
+)
+
+// ScvProbeInterval is how long the result of probing the SCV API is reused,
+// so readiness probes do not each cost a request.
+const ScvProbeInterval = 30 * time.Second
+
+const PassthroughDetail = "passthrough: no SCV client, scheduling without GPU constraints"
+
+// scvProbe caches the outcome of the last probe of the SCV API.
+type scvProbe struct {
+	mu      sync.Mutex
+	checked time.Time
+	err     error
+}
+
+func (p *scvProbe) check(now time.Time, probe func() error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.checked.IsZero() || now.Sub(p.checked) >= ScvProbeInterval {
+		p.err = probe()
+		p.checked = now
+	}
+	return p.err
+}
+
+func (y *Yoda) Readiness() *admin.Readiness {
+	r := admin.NewReadiness()
+	r.AddCheck("pod-informer", func() error {
+		if !y.handle.SharedInformerFactory().Core().V1().Pods().Informer().HasSynced() {
+			return errors.New("pod informer not synced")
+		}
+		return nil
+	})
+	clientProbe := &scvProbe{}
+	r.AddDetailedCheck("scv-client", func() (string, error) {
+		c := y.scvs.Client()
+		if c == nil {
+			return PassthroughDetail, nil
+		}
+		return "", clientProbe.check(y.clock.Now(), func() error {
+			return c.List(context.TODO(), &scv.ScvList{}, client.Limit(1))
+		})
+	})
+	if y.scvs.Enabled() {
+		syncProbe := &scvProbe{}
+		r.AddDetailedCheck("scv-cache", func() (string, error) {
+			if y.passthrough() {
+				return PassthroughDetail, nil
+			}
+			if y.scvs.Synced() {
+				return "", nil
+			}
+			// Fill the cache rather than waiting for the first scheduling cycle to.
+			return "", syncProbe.check(y.clock.Now(), func() error {
+				return y.scvs.List(context.TODO(), &scv.ScvList{})
+			})
+		})
+	}
+	if y.args.PublishExtendedResources {
+		r.AddCheck("publisher", func() error {
+			if atomic.LoadInt32(&y.published) == 0 {
+				return errors.New("extended resources not published yet")
+			}
+			return nil
+		})
+	}
+	return r
+}
@@ -0,0 +1,95 @@
+package yoda
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/scvcache"
+)
+
+func TestReadinessReport(t *testing.T) {
+	tests := []struct {
+		name        string
+		passthrough bool
+		listFirst   bool
+		want        map[string]string
+	}{
+		{
+			name:      "client usable and cache synced",
+			listFirst: true,
+			want:      map[string]string{"pod-informer": "ok", "scv-client": "ok", "scv-cache": "ok"},
+		},
+		{
+			name: "readiness fills an unsynced cache",
+			want: map[string]string{"pod-informer": "ok", "scv-client": "ok", "scv-cache": "ok"},
+		},
+		{
+			name:        "passthrough is ready with a detail",
+			passthrough: true,
+			want:        map[string]string{"pod-informer": "ok", "scv-client": PassthroughDetail, "scv-cache": PassthroughDetail},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}
+			y := newTestYoda(t, pod, nil)
+			y.scvs = scvcache.New(testScvClient(t, testScv("node", testCard(8000, 8000))), y.clock, time.Minute)
+			if tt.listFirst {
+				if err := y.scvs.List(context.Background(), &scv.ScvList{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.passthrough {
+				y.scvs.SetClient(nil)
+			}
+			stop := make(chan struct{})
+			defer close(stop)
+			informers := y.handle.SharedInformerFactory()
+			informers.Core().V1().Pods().Informer()
+			informers.Start(stop)
+			informers.WaitForCacheSync(stop)
+
+			report := y.Readiness().Report()
+			if !report.Ready {
+				t.Errorf("Report() is not ready: %v", report.Checks)
+			}
+			for check, want := range tt.want {
+				if got := report.Checks[check]; got != want {
+					t.Errorf("check %v = %q, want %q", check, got, want)
+				}
+			}
+			if !tt.passthrough && !y.scvs.Synced() {
+				t.Error("SCV cache not synced after a ready report")
+			}
+		})
+	}
+}
+
+func TestScvProbeReusesResult(t *testing.T) {
+	start := time.Now()
+	calls := 0
+	probe := func() error {
+		calls++
+		return errors.New("unreachable")
+	}
+	p := &scvProbe{}
+	for _, at := range []time.Duration{0, time.Second, ScvProbeInterval - time.Second} {
+		if err := p.check(start.Add(at), probe); err == nil {
+			t.Fatal("check() lost the probe error")
+		}
+	}
+	if calls != 1 {
+		t.Errorf("probed %v times within the interval, want 1", calls)
+	}
+	p.check(start.Add(ScvProbeInterval), probe)
+	if calls != 2 {
+		t.Errorf("probed %v times after the interval, want 2", calls)
+	}
+}
@@ -0,0 +1,48 @@
+package yoda
+
+import (
+	"k8s.io/klog"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// setRequirementParsers registers the parsers that need the plugin's
+// configuration or informers and installs the configured chain.
+func (y *Yoda) setRequirementParsers() {
+	filter.ResetRequirementParsers()
+	filter.RegisterRequirementParser(filter.NewProfileParser(y.args.RequirementProfiles))
+	filter.RegisterRequirementParser(filter.NewResourceLimitParser(y.args.InitContainerGpuPolicy))
+	filter.RegisterRequirementParser(filter.NewKubeflowParser(y.args.InitContainerGpuPolicy))
+	for _, name := range y.args.RequirementParsers {
+		if name == filter.ConfigMapRefParser {
+			lister := y.handle.SharedInformerFactory().Core().V1().ConfigMaps().Lister()
+			filter.RegisterRequirementParser(filter.NewConfigMapParser(lister))
+		}
+	}
+	names := y.args.RequirementParsers
+	if len(names) == 0 {
+		names = filter.DefaultRequirementParsers
+	}
+	if defaults := y.imageDefaults(); len(defaults) > 0 {
+		filter.RegisterRequirementParser(filter.NewImageDefaultParser(defaults))
+		names = append(append([]string(nil), names...), filter.ImageDefaultParser)
+	}
+	if err := filter.SetRequirementParsers(names); err != nil {
+		klog.Warningf("invalid requirementParsers %v, falling back to %q: %v", y.args.RequirementParsers, filter.LabelParser, err)
+	}
+	filter.SetUnrequestedNoGpu(y.args.UnrequestedMeansNoGpu)
+}
+
+// imageDefaults parses the configured image defaults, skipping invalid entries.
+func (y *Yoda) imageDefaults() map[string]filter.Requirement {
+	defaults := map[string]filter.Requirement{}
+	for prefix, spec := range y.args.ImageDefaults {
+		r, err := filter.ParseRequirementSpec(spec)
+		if err != nil {
+			klog.Warningf("invalid imageDefaults entry for %q, ignoring it: %v", prefix, err)
+			continue
+		}
+		defaults[prefix] = r
+	}
+	return defaults
+}
@@ -0,0 +1,44 @@
+package yoda
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+func TestImageDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		image  string
+		labels map[string]string
+		want   filter.Requirement
+	}{
+		{name: "matching image gets the default", image: "registry.local/train/resnet:v2", want: filter.Requirement{Number: "2", Memory: "8000"}},
+		{name: "longest matching prefix wins", image: "registry.local/train/bert:v1", want: filter.Requirement{Number: "4", Memory: "16000"}},
+		{name: "explicit labels win over the default", image: "registry.local/train/resnet:v2", labels: map[string]string{"scv/memory": "1000"}, want: filter.Requirement{Memory: "1000"}},
+		{name: "other images need explicit requirements", image: "registry.local/serve/api:v1", want: filter.Requirement{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod", Labels: tt.labels},
+				Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "main", Image: tt.image}}},
+			}
+			y := newTestYoda(t, pod, nil)
+			y.args.ImageDefaults = map[string]string{
+				"registry.local/train/":      "number=2,memory=8000",
+				"registry.local/train/bert":  "number=4,memory=16000",
+				"registry.local/serve/other": "invalid",
+			}
+			y.setRequirementParsers()
+			defer filter.ResetRequirementParsers()
+			defer filter.SetUnrequestedNoGpu(false)
+			if got := filter.PodRequirement(pod); got != tt.want {
+				t.Errorf("PodRequirement() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package yoda
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// ExtendedMemoryResource is the GPU memory still free on the node. It is
+// net of running pods, so it must not share a name with the resource pods
+// request, or NodeResourcesFit would subtract their requests a second time.
+const ExtendedMemoryResource = "yoda.gpu/free-memory-mb"
+
+// AllocatableMemory is the free GPU memory SCV reports minus what Yoda has
+// reserved on the node since.
+func (y *Yoda) AllocatableMemory(s *scv.Scv) uint64 {
+	reserved := y.ledger.ReservedMemory(s.GetName())
+	if reserved >= s.Status.FreeMemorySum {
+		return 0
+	}
+	return s.Status.FreeMemorySum - reserved
+}
+
+func (y *Yoda) publishExtendedResources() {
+	if y.passthrough() {
+		return
+	}
+	scvList := scv.ScvList{}
+	if err := y.scvs.List(context.TODO(), &scvList); err != nil {
+		klog.Errorf("Get Scv List Error: %v", err)
+		return
+	}
+	for i := range scvList.Items {
+		s := &scvList.Items[i]
+		if err := y.updateNodeMemory(s.GetName(), y.AllocatableMemory(s)); err != nil {
+			klog.Errorf("Publish Extended Resource on Node %v Error: %v", s.GetName(), err)
+		}
+	}
+	atomic.StoreInt32(&y.published, 1)
+}
+
+// updateNodeMemory merge-patches the extended resource into the node's status,
+// leaving the rest of the status to the kubelet. The patch carries the
+// resourceVersion it was computed from, so a concurrent change is retried.
+func (y *Yoda) updateNodeMemory(nodeName string, memory uint64) error {
+	quantity := *resource.NewQuantity(filter.Uint64ToInt64(memory), resource.DecimalSI)
+	nodes := y.handle.ClientSet().CoreV1().Nodes()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := nodes.Get(nodeName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		capacity, ok := node.Status.Capacity[ExtendedMemoryResource]
+		allocatable := node.Status.Allocatable[ExtendedMemoryResource]
+		if ok && capacity.Cmp(quantity) == 0 && allocatable.Cmp(quantity) == 0 {
+			return nil
+		}
+		resources := v1.ResourceList{ExtendedMemoryResource: quantity}
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]string{"resourceVersion": node.GetResourceVersion()},
+			"status":   map[string]v1.ResourceList{"capacity": resources, "allocatable": resources},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = nodes.Patch(nodeName, types.MergePatchType, patch, "status")
+		return err
+	})
+}
+
+// releaseDeletedPods drops the reservations of pods that no longer exist. Once
+// a pod runs, the device plugin has allocated its cards and SCV's free memory
+// counts it, so its SCV entry is removed and the ledger stops counting its memory.
+func (y *Yoda) releaseDeletedPods() {
+	y.handle.SharedInformerFactory().Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			pod, ok := newObj.(*v1.Pod)
+			if !ok || pod.Status.Phase != v1.PodRunning {
+				return
+			}
+			if r, ok := y.ledger.Get(pod.GetUID()); ok && y.ledger.Settle(pod.GetUID()) {
+				y.removeScvReservation(context.TODO(), r.Node, pod.GetUID())
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			pod, ok := obj.(*v1.Pod)
+			if !ok {
+				return
+			}
+			if r, ok := y.ledger.Get(pod.GetUID()); ok && !r.Settled {
+				y.removeScvReservation(context.TODO(), r.Node, pod.GetUID())
+			}
+			y.ledger.Free(pod.GetUID(), y.clock.Now())
+		},
+	})
+}
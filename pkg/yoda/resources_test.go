@@ -0,0 +1,79 @@
+package yoda
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/ledger"
+)
+
+func TestPublishExtendedResources(t *testing.T) {
+	tests := []struct {
+		name   string
+		pods   map[string]ledger.Reservation
+		settle []string
+		want   int64
+	}{
+		{name: "SCV free memory without reservations", want: 12000},
+		{
+			name: "minus the memory reserved on each card",
+			pods: map[string]ledger.Reservation{
+				"a": {Node: "node", Cards: []int{0, 1}, Memory: 2000},
+				"b": {Node: "node", Cards: []int{1}, Memory: 1000},
+			},
+			want: 7000,
+		},
+		{
+			name: "running pods already counted by SCV are not subtracted again",
+			pods: map[string]ledger.Reservation{
+				"a": {Node: "node", Cards: []int{0, 1}, Memory: 2000},
+				"b": {Node: "node", Cards: []int{1}, Memory: 1000},
+			},
+			settle: []string{"a"},
+			want:   11000,
+		},
+		{
+			name: "never below zero",
+			pods: map[string]ledger.Reservation{
+				"a": {Node: "node", Cards: []int{0, 1}, Memory: 7000},
+			},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}
+			y := newTestYoda(t, pod, []*v1.Node{testNode("node")}, testScv("node", testCard(6000, 8000), testCard(6000, 8000)))
+			for uid, r := range tt.pods {
+				y.ledger.Reserve(types.UID(uid), r)
+			}
+			for _, uid := range tt.settle {
+				y.ledger.Settle(types.UID(uid))
+			}
+			y.publishExtendedResources()
+			node, err := y.handle.ClientSet().CoreV1().Nodes().Get("node", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := node.Status.Allocatable[filter.GpuMemoryResource]; ok {
+				t.Errorf("published %v, the resource pods request", filter.GpuMemoryResource)
+			}
+			for _, a := range y.handle.ClientSet().(*fake.Clientset).Actions() {
+				if a.GetResource().Resource == "nodes" && (a.GetVerb() == "update" || a.GetVerb() == "patch" && a.GetSubresource() != "status") {
+					t.Errorf("node written with %v %v, want only patches of nodes/status", a.GetVerb(), a.GetSubresource())
+				}
+			}
+			for _, list := range []v1.ResourceList{node.Status.Capacity, node.Status.Allocatable} {
+				q := list[ExtendedMemoryResource]
+				if got := q.Value(); got != tt.want {
+					t.Errorf("published %v = %v, want %v", ExtendedMemoryResource, got, tt.want)
+				}
+			}
+		})
+	}
+}
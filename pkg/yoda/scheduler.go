@@ -3,10 +3,15 @@ package yoda
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"sync"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog"
 	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
@@ -15,10 +20,15 @@ import (
 
 	scv "github.com/NJUPT-ISL/SCV/api/v1"
 
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/admin"
 	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/collection"
 	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/ledger"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/metrics"
 	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/score"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/scvcache"
 	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/sort"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/stats"
 )
 
 const (
@@ -27,23 +37,37 @@ const (
 
 var (
 	_ framework.QueueSortPlugin  = &Yoda{}
+	_ framework.PreFilterPlugin  = &Yoda{}
 	_ framework.FilterPlugin     = &Yoda{}
 	_ framework.PostFilterPlugin = &Yoda{}
 	_ framework.ScorePlugin      = &Yoda{}
 	_ framework.ScoreExtensions  = &Yoda{}
+	_ framework.ReservePlugin    = &Yoda{}
+	_ framework.PreBindPlugin    = &Yoda{}
+	_ framework.UnreservePlugin  = &Yoda{}
 
 	scheme = runtime.NewScheme()
 )
 
-type Args struct {
-	KubeConfig string `json:"kubeconfig,omitempty"`
-	Master     string `json:"master,omitempty"`
-}
-
 type Yoda struct {
-	args      *Args
-	handle    framework.FrameworkHandle
-	scvClient client.Client
+	args       *Args
+	handle     framework.FrameworkHandle
+	scvs       *scvcache.Cache
+	clock      clock.Clock
+	predicates []filter.Predicate
+	rejections *stats.Window
+	ledger     *ledger.Ledger
+	quarantine *stats.Quarantine
+	history    *stats.PlacementHistory
+	scores     *stats.ScoreHistory
+	ties       *score.TieBreaker
+	// maintenance is 1 while new GPU pods must not be placed
+	maintenance int32
+	// published is 1 once extended resources were published at least once
+	published int32
+
+	sloMu          sync.Mutex
+	lastSLOWarning time.Time
 }
 
 func (y *Yoda) Name() string {
@@ -55,40 +79,201 @@ func New(configuration *runtime.Unknown, f framework.FrameworkHandle) (framework
 	if err := framework.DecodeInto(configuration, args); err != nil {
 		return nil, err
 	}
-	klog.V(3).Infof("get plugin config args: %+v", args)
-	return &Yoda{
-		args:      args,
-		handle:    f,
-		scvClient: NewScvClient(),
-	}, nil
+	args.SetDefaults()
+	metrics.Register()
+	l := ledger.New()
+	predicates := args.Predicates(l)
+	for i, p := range predicates {
+		predicates[i] = filter.WithObserver(p, metrics.ObservePredicateLatency)
+	}
+	c := clock.RealClock{}
+	scvClient, err := NewScvClient(args)
+	if err != nil {
+		if args.OnScvClientFailure == ScvClientFailureFail {
+			return nil, fmt.Errorf("construct SCV client: %v", err)
+		}
+		klog.Warningf("no SCV client, scheduling without GPU constraints: %v", err)
+	}
+	y := &Yoda{
+		args:       args,
+		handle:     f,
+		scvs:       scvcache.New(scvClient, c, time.Duration(args.ScvCacheTTLSeconds)*time.Second),
+		clock:      c,
+		predicates: predicates,
+		rejections: stats.NewWindow(c, time.Duration(args.RejectionWindowMinutes)*time.Minute, time.Minute),
+		ledger:     l,
+		history:    stats.NewPlacementHistory(c, time.Duration(args.PlacementHistoryTTLMinutes)*time.Minute),
+		ties:       score.NewTieBreaker(args.EqualScoreFallback, args.EqualScoreFallbackSeed),
+	}
+	y.SetMaintenanceMode(args.MaintenanceMode)
+	if args.RecordScoreHistory {
+		y.scores = stats.NewScoreHistory(args.ScoreHistorySize)
+	}
+	for _, r := range score.RunSelfTest(DefaultScoreOptions()) {
+		if !r.Passed {
+			klog.Warningf("scoring self-test %q failed: expected %v, got %v %v", r.Name, r.Expected, r.Actual, r.Error)
+		}
+	}
+	y.setRequirementParsers()
+	if scvClient == nil && args.OnScvClientFailure == ScvClientFailureRetry {
+		y.retryScvClient()
+	}
+	klog.V(2).Infof("yoda effective config args: %+v", y.EffectiveArgs())
+	y.releaseDeletedPods()
+	if args.PlacementHistoryWeight > 0 {
+		y.recordSuccessfulPlacements()
+	}
+	if args.OomQuarantineThreshold > 0 {
+		y.watchGpuOOMs()
+	}
+	if args.PublishExtendedResources {
+		go wait.Until(y.publishExtendedResources, time.Duration(args.PublishIntervalSeconds)*time.Second, wait.NeverStop)
+	}
+	if args.AdminAddress != "" {
+		server := admin.NewServer(args.AdminAddress)
+		y.RegisterAdminHandlers(server)
+		server.Start()
+	}
+	return y, nil
+}
+
+func (y *Yoda) RegisterAdminHandlers(server *admin.Server) {
+	server.HandleJSON("/rejections", func(r *http.Request) (interface{}, error) {
+		if r.Method == http.MethodDelete {
+			y.rejections.Reset()
+		}
+		return stats.SummarizeRejections(y.rejections), nil
+	})
+	server.HandleJSON("/maintenance", y.handleMaintenance)
+	server.Handle("/readyz", y.Readiness())
+	server.HandleJSON("/quarantine", func(r *http.Request) (interface{}, error) {
+		if y.quarantine == nil {
+			return map[string]time.Time{}, nil
+		}
+		return y.quarantine.Keys(), nil
+	})
+	server.HandleJSON("/scores", func(r *http.Request) (interface{}, error) {
+		if y.scores == nil {
+			return map[string][]stats.ScoreSample{}, nil
+		}
+		return y.scores.Samples(), nil
+	})
+	server.HandleJSON("/compare", func(r *http.Request) (interface{}, error) {
+		q := r.URL.Query()
+		return y.CompareNodes(r.Context(), q.Get("pod"), q.Get("a"), q.Get("b"))
+	})
+	server.HandleJSON("/config", func(r *http.Request) (interface{}, error) {
+		return y.EffectiveArgs(), nil
+	})
+}
+
+// EffectiveArgs returns the configuration in effect after defaults and runtime overrides.
+func (y *Yoda) EffectiveArgs() Args {
+	args := *y.args
+	args.MaintenanceMode = y.InMaintenance()
+	return args
+}
+
+func (y *Yoda) PreFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod) *framework.Status {
+	ignored, err := score.ParseIgnoredMetrics(pod)
+	if err != nil {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, err.Error())
+	}
+	if _, _, err := filter.PodMemoryPercentOfMax(pod); err != nil {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, err.Error())
+	}
+	if !filter.PodRequestsNoGpu(pod) {
+		if !y.namespaceAllowed(pod) {
+			return framework.NewStatus(framework.UnschedulableAndUnresolvable, "namespace "+pod.GetNamespace()+" is not allowed to use GPU nodes")
+		}
+		if reason := y.checkPodLimits(pod); reason != "" {
+			return framework.NewStatus(framework.UnschedulableAndUnresolvable, reason)
+		}
+	}
+	state.Lock()
+	state.Write(score.IgnoreMetricsStateKey, ignored)
+	state.Write(CycleTimingsStateKey, NewCycleTimings())
+	if y.shouldSkipFilter(pod) {
+		state.Write(SkipStateKey, skipFilter{})
+	}
+	state.Unlock()
+	return framework.NewStatus(framework.Success, "")
+}
+
+func (y *Yoda) PreFilterExtensions() framework.PreFilterExtensions {
+	return nil
 }
 
 func (y *Yoda) Filter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, node *nodeinfo.NodeInfo) *framework.Status {
 	klog.V(3).Infof("filter pod: %v, node: %v", pod.Name, node.Node().Name)
+	if y.InMaintenance() && !filter.PodRequestsNoGpu(pod) {
+		y.rejections.Add("maintenance")
+		return framework.NewStatus(framework.Unschedulable, "Node:"+node.Node().Name+" "+MaintenanceReason)
+	}
+	if y.Quarantined(node.Node().Name) && !filter.PodRequestsNoGpu(pod) {
+		y.rejections.Add("oom-quarantine")
+		return framework.NewStatus(framework.Unschedulable, "Node:"+node.Node().Name+" "+QuarantineReason)
+	}
+
+	if filterSkipped(state) || y.passthrough() {
+		return framework.NewStatus(framework.Success, "")
+	}
 
+	start := y.clock.Now()
 	currentScv := &scv.Scv{}
-	err := y.scvClient.Get(ctx, types.NamespacedName{Name: node.Node().GetName()}, currentScv)
+	err := y.scvs.Get(ctx, node.Node().GetName(), scvcache.ReadMode(y.args.FilterReadMode), currentScv)
+	y.observePhase(state, PhaseFetch, start)
 	if err != nil {
+		if filter.PodRequestsNoGpu(pod) && apierrors.IsNotFound(err) {
+			return framework.NewStatus(framework.Success, "")
+		}
 		klog.Errorf("Get SCV Error: %v", err)
 		return framework.NewStatus(framework.Unschedulable, "Node:"+node.Node().Name+" "+err.Error())
 	}
-	if ok, number := filter.PodFitsNumber(pod, currentScv); ok {
-		isFitsMemory, _ := filter.PodFitsMemory(number, pod, currentScv)
-		isFitsClock, _ := filter.PodFitsClock(number, pod, currentScv)
-		if isFitsMemory && isFitsClock {
-			return framework.NewStatus(framework.Success, "")
-		}
+	filter.ApplyEccReserved(currentScv, node.Node())
+	start = y.clock.Now()
+	p, reason := filter.RunPredicates(y.predicates, pod, node, currentScv)
+	y.observePhase(state, PhaseFilter, start)
+	if p != nil {
+		y.rejections.Add(p.Name())
+		return framework.NewStatus(framework.Unschedulable, "Node:"+node.Node().Name+" "+reason)
 	}
-	return framework.NewStatus(framework.Unschedulable, "Node:"+node.Node().Name)
+	return framework.NewStatus(framework.Success, "")
 }
 
 func (y *Yoda) PostFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodes []*v1.Node, filteredNodesStatuses framework.NodeToStatusMap) *framework.Status {
 	klog.V(3).Infof("collect info for scheduling pod: %v", pod.Name)
+	if y.passthrough() {
+		return framework.NewStatus(framework.Success, "")
+	}
+	start := y.clock.Now()
 	scvList := scv.ScvList{}
-	if err := y.scvClient.List(ctx, &scvList); err != nil {
+	err := y.scvs.List(ctx, &scvList)
+	y.observePhase(state, PhaseFetch, start)
+	if err != nil {
 		klog.Errorf("Get Scv List Error: %v", err)
 		return framework.NewStatus(framework.Error, err.Error())
 	}
+	defer y.observePhase(state, PhaseCollect, y.clock.Now())
+	return y.collectScoringData(state, pod, nodes, scvList)
+}
+
+// collectScoringData records the cluster-wide data score terms compare nodes against.
+func (y *Yoda) collectScoringData(state *framework.CycleState, pod *v1.Pod, nodes []*v1.Node, scvList scv.ScvList) *framework.Status {
+	if y.args.ColdSpareCount > 0 {
+		collection.CollectColdSpares(state, scvList, y.ledger, y.args.ColdSpareCount)
+	}
+	if y.args.PreferConsolidation && y.args.IdleNodePenaltyWeight > 0 {
+		collection.CollectConsolidation(state, pod, nodes, scvList, y.ledger)
+	}
+	if y.args.MissingDataPolicy == score.MissingDataExcludeTerm {
+		opts := y.args.ScoreOptions()
+		opts.Ledger = y.ledger
+		score.CollectMissingTerms(state, opts, pod, nodes)
+	}
+	if y.args.VendorBalanceWeight > 0 {
+		collection.CollectVendorUsage(state, scvList, y.nodeVendors(), y.ledger)
+	}
 	return collection.CollectMaxValues(state, pod, scvList)
 }
 
@@ -97,6 +282,9 @@ func (y *Yoda) Less(podInfo1, podInfo2 *framework.PodInfo) bool {
 }
 
 func (y *Yoda) Score(ctx context.Context, state *framework.CycleState, p *v1.Pod, nodeName string) (int64, *framework.Status) {
+	if y.passthrough() {
+		return 0, framework.NewStatus(framework.Success, "")
+	}
 	// Get Node Info
 	nodeInfo, err := y.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
 	if err != nil {
@@ -104,68 +292,94 @@ func (y *Yoda) Score(ctx context.Context, state *framework.CycleState, p *v1.Pod
 	}
 
 	// Get Scv Info
+	start := y.clock.Now()
 	currentScv := &scv.Scv{}
-	err = y.scvClient.Get(ctx, types.NamespacedName{Name: nodeName}, currentScv)
+	err = y.scvs.Get(ctx, nodeName, scvcache.ReadMode(y.args.ScoreReadMode), currentScv)
+	y.observePhase(state, PhaseFetch, start)
+	if filter.PodRequestsNoGpu(p) {
+		// Pods without GPU requests prefer nodes without GPUs
+		if apierrors.IsNotFound(err) || (err == nil && currentScv.Status.CardNumber == 0) {
+			return framework.MaxNodeScore, framework.NewStatus(framework.Success, "")
+		}
+		return framework.MinNodeScore, framework.NewStatus(framework.Success, "")
+	}
 	if err != nil {
 		klog.Errorf("Get SCV Error: %v", err)
 		return 0, framework.NewStatus(framework.Error, fmt.Sprintf("Score Node Error: %v", err))
 	}
+	filter.ApplyEccReserved(currentScv, nodeInfo.Node())
 
-	uNodeScore, err := score.CalculateScore(currentScv, state, p, nodeInfo)
-	if err != nil {
-		return 0, framework.NewStatus(framework.Error, fmt.Sprintf("Score Node Error: %v", err))
+	defer y.observePhase(state, PhaseScore, y.clock.Now())
+	r, ok := y.breakdownWithin(state, p, nodeInfo, currentScv)
+	if !ok {
+		return score.ToNodeScore(y.args.NeutralScore), framework.NewStatus(framework.Success, "")
 	}
-	nodeScore := filter.Uint64ToInt64(uNodeScore)
-	return nodeScore, framework.NewStatus(framework.Success, "")
+	if r.err != nil {
+		return 0, framework.NewStatus(framework.Error, fmt.Sprintf("Score Node Error: %v", r.err))
+	}
+	if r.fits {
+		recordCandidateCards(state, nodeName, r.cards)
+	}
+	if filter.PodAnnotationIsTrue(p, DebugAnnotation) {
+		recordScoreDetail(state, nodeName, r.b)
+	}
+	return score.ToNodeScore(r.b.Total()), framework.NewStatus(framework.Success, "")
 }
 
 func (y *Yoda) NormalizeScore(ctx context.Context, state *framework.CycleState, p *v1.Pod, scores framework.NodeScoreList) *framework.Status {
-	var (
-		highest int64 = 0
-		lowest        = scores[0].Score
-	)
-	for _, nodeScore := range scores {
-		if nodeScore.Score < lowest {
-			lowest = nodeScore.Score
-		}
-		if nodeScore.Score > highest {
-			highest = nodeScore.Score
-		}
+	if len(scores) == 0 {
+		return framework.NewStatus(framework.Success, "")
 	}
-
-	if highest == lowest {
-		lowest --
+	score.Normalize(scores)
+	y.ties.Break(scores)
+	if y.scores != nil {
+		now := y.clock.Now()
+		for _, s := range scores {
+			y.scores.Record(s.Name, stats.ScoreSample{Time: now, Pod: p.Namespace + "/" + p.Name, Score: s.Score})
+		}
 	}
+	y.checkCycleSLO(state, p)
+	return framework.NewStatus(framework.Success, "")
+}
 
-	// Set Range to [0-100]
-	for i, nodeScore := range scores {
-		scores[i].Score = (nodeScore.Score - lowest) * framework.MaxNodeScore / (highest - lowest)
-		klog.V(3).Infof("node: %v, final Score: %v", scores[i].Name, scores[i].Score)
+// breakdown scores the node and selects the pod's cards there. It only reads
+// the cycle state; callers record the cards.
+func (y *Yoda) breakdown(state *framework.CycleState, p *v1.Pod, nodeInfo *nodeinfo.NodeInfo, s *scv.Scv) breakdownResult {
+	opts := y.args.ScoreOptions()
+	opts.Now = y.clock.Now()
+	opts.Ledger = y.ledger
+	opts.History = y.history
+	opts.IgnoredMetrics = score.ReadIgnoredMetrics(state)
+	b, err := score.CalculateBreakdown(opts, s, state, p, nodeInfo)
+	if err != nil {
+		return breakdownResult{err: err}
 	}
-	return framework.NewStatus(framework.Success, "")
+	cards, _, fits := filter.SelectCards(p, s, y.reservedMemory(p, s))
+	return breakdownResult{b: score.Sanitize(b, opts.NeutralScore, nodeInfo.Node().GetName()), cards: cards, fits: fits}
 }
 
 func (y *Yoda) ScoreExtensions() framework.ScoreExtensions {
 	return y
 }
 
-func NewScvClient() client.Client {
-	err := scv.AddToScheme(scheme)
+func NewScvClient(args *Args) (client.Client, error) {
+	err := addScvToScheme(scheme, args.ScvGroupVersionKind)
 	if err != nil {
 		klog.Errorf("Add SCV CRD to Scheme Error: %v", err)
-		return nil
+		return nil, err
 	}
 	config, err := clientcmd.BuildConfigFromFlags("", "")
 	if err != nil {
 		klog.Errorf("Get Kubernetes Config Error: %v", err)
-		return nil
+		return nil, err
 	}
+	ApplyClientLimits(config, args)
 	c, err := client.New(config, client.Options{
 		Scheme: scheme,
 	})
 	if err != nil {
 		klog.Errorf("New Client Error: %v", err)
-		return nil
+		return nil, err
 	}
-	return c
+	return c, nil
 }
@@ -0,0 +1,484 @@
+package yoda
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	nodeinfosnapshot "k8s.io/kubernetes/pkg/scheduler/nodeinfo/snapshot"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/metrics"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/score"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/scvcache"
+)
+
+func TestNonGpuPodPlacement(t *testing.T) {
+	tests := []struct {
+		name      string
+		strict    bool
+		node      string
+		wantScore int64
+		wantFits  bool
+	}{
+		{name: "deprioritized on a GPU node", node: "gpu", wantScore: framework.MinNodeScore, wantFits: true},
+		{name: "rejected on a GPU node under strict isolation", strict: true, node: "gpu", wantScore: framework.MinNodeScore, wantFits: false},
+		{name: "preferred on a node without GPUs", node: "cpu", wantScore: framework.MaxNodeScore, wantFits: true},
+		{name: "allowed on a node without GPUs under strict isolation", strict: true, node: "cpu", wantScore: framework.MaxNodeScore, wantFits: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web"}}
+			nodes := []*v1.Node{testNode("gpu"), testNode("cpu")}
+			y := newTestYoda(t, pod, nodes, testScv("gpu", testCard(8000, 8000)), testScv("cpu"))
+			y.args.UnrequestedMeansNoGpu = true
+			y.args.StrictGpuNodeIsolation = tt.strict
+			y.predicates = y.args.Predicates(y.ledger)
+			y.setRequirementParsers()
+			defer filter.SetUnrequestedNoGpu(false)
+			defer filter.ResetRequirementParsers()
+
+			state := framework.NewCycleState()
+			if status := y.PreFilter(ctx, state, pod); !status.IsSuccess() {
+				t.Fatalf("PreFilter() = %v", status.Message())
+			}
+			nodeInfo, err := y.handle.SnapshotSharedLister().NodeInfos().Get(tt.node)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status := y.Filter(ctx, state, pod, nodeInfo); status.IsSuccess() != tt.wantFits {
+				t.Errorf("Filter() = %v, want success %v", status.Message(), tt.wantFits)
+			}
+			score, status := y.Score(ctx, state, pod, tt.node)
+			if !status.IsSuccess() || score != tt.wantScore {
+				t.Errorf("Score() = %v, %v, want %v", score, status.Message(), tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestPhaseReadModes(t *testing.T) {
+	ctx := context.Background()
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod", Labels: map[string]string{"scv/memory": "4000"}}}
+	y := newTestYoda(t, pod, []*v1.Node{testNode("node")})
+	cl := testScvClient(t, testScv("node", testCard(8000, 8000)))
+	y.scvs = scvcache.New(cl, y.clock, time.Minute)
+	y.args.FilterReadMode = string(scvcache.ReadCache)
+	y.args.ReserveReadMode = string(scvcache.ReadLive)
+	nodeInfo, err := y.handle.SnapshotSharedLister().NodeInfos().Get("node")
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := framework.NewCycleState()
+	if status := y.Filter(ctx, state, pod, nodeInfo); !status.IsSuccess() {
+		t.Fatalf("Filter() = %v", status.Message())
+	}
+	// The card fills up after Filter cached the SCV.
+	s := &scv.Scv{}
+	if err := cl.Get(ctx, types.NamespacedName{Name: "node"}, s); err != nil {
+		t.Fatal(err)
+	}
+	s.Status.CardList[0].FreeMemory = 1000
+	if err := cl.Update(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	if status := y.Filter(ctx, state, pod, nodeInfo); !status.IsSuccess() {
+		t.Errorf("Filter() with a cached SCV = %v, want success", status.Message())
+	}
+	if status := y.Reserve(ctx, state, pod, "node"); status.IsSuccess() {
+		t.Error("Reserve() with a live read succeeded on a full card")
+	}
+	// Reserve's live read refreshed the cache, so Filter now agrees with it.
+	if status := y.Filter(ctx, state, pod, nodeInfo); status.IsSuccess() {
+		t.Error("Filter() after Reserve's live read still passes the full card")
+	}
+}
+
+func TestSelfTestWithDefaultArgs(t *testing.T) {
+	for _, r := range score.RunSelfTest(DefaultScoreOptions()) {
+		t.Run(r.Name, func(t *testing.T) {
+			if !r.Passed {
+				t.Errorf("expected %v, got %v %v", r.Expected, r.Actual, r.Error)
+			}
+		})
+	}
+}
+
+func TestPreFilterRejectsInvalidMemoryPercent(t *testing.T) {
+	tests := []struct {
+		percent string
+		want    framework.Code
+	}{
+		{percent: "50%", want: framework.Success},
+		{percent: "150", want: framework.UnschedulableAndUnresolvable},
+		{percent: "half", want: framework.UnschedulableAndUnresolvable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.percent, func(t *testing.T) {
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod",
+				Annotations: map[string]string{filter.MemoryPercentOfMaxAnnotation: tt.percent}}}
+			y := newTestYoda(t, pod, []*v1.Node{testNode("node")})
+			if status := y.PreFilter(context.Background(), framework.NewCycleState(), pod); status.Code() != tt.want {
+				t.Errorf("PreFilter() = %v %v, want %v", status.Code(), status.Message(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPreFilterPodLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		number string
+		memory string
+		want   framework.Code
+		cap    string
+	}{
+		{name: "within limits", number: "4", memory: "40000", want: framework.Success},
+		{name: "too many cards", number: "5", memory: "1000", want: framework.UnschedulableAndUnresolvable, cap: "maxPodGpuCards"},
+		{name: "too much memory", number: "4", memory: "50000", want: framework.UnschedulableAndUnresolvable, cap: "maxPodGpuMemory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod",
+				Labels: map[string]string{"scv/number": tt.number, "scv/memory": tt.memory}}}
+			y := newTestYoda(t, pod, []*v1.Node{testNode("node")})
+			y.args.MaxPodGpuCards = 4
+			y.args.MaxPodGpuMemory = "160GB"
+			status := y.PreFilter(context.Background(), framework.NewCycleState(), pod)
+			if status.Code() != tt.want {
+				t.Fatalf("PreFilter() = %v %v, want %v", status.Code(), status.Message(), tt.want)
+			}
+			if tt.cap != "" && !strings.Contains(status.Message(), tt.cap) {
+				t.Errorf("PreFilter() message %q does not name %v", status.Message(), tt.cap)
+			}
+		})
+	}
+}
+
+func TestPreFilterAllowedNamespaces(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowed   []string
+		namespace string
+		labels    map[string]string
+		want      framework.Code
+	}{
+		{name: "allowed namespace", allowed: []string{"ml", "research"}, namespace: "research", want: framework.Success},
+		{name: "disallowed namespace", allowed: []string{"ml", "research"}, namespace: "default", want: framework.UnschedulableAndUnresolvable},
+		{name: "empty allowlist", namespace: "default", want: framework.Success},
+		{name: "pod without GPUs", allowed: []string{"ml"}, namespace: "default", labels: map[string]string{"scv/number": "0"}, want: framework.Success},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := tt.labels
+			if labels == nil {
+				labels = map[string]string{"scv/memory": "4000"}
+			}
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: tt.namespace, UID: "pod", Labels: labels}}
+			y := newTestYoda(t, pod, []*v1.Node{testNode("node")})
+			y.args.AllowedNamespaces = tt.allowed
+			status := y.PreFilter(context.Background(), framework.NewCycleState(), pod)
+			if status.Code() != tt.want {
+				t.Fatalf("PreFilter() = %v %v, want %v", status.Code(), status.Message(), tt.want)
+			}
+			if tt.want != framework.Success && !strings.Contains(status.Message(), tt.namespace) {
+				t.Errorf("PreFilter() message %q does not name namespace %v", status.Message(), tt.namespace)
+			}
+		})
+	}
+}
+
+func TestFilterRecordsRejections(t *testing.T) {
+	ctx := context.Background()
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod", Labels: map[string]string{"scv/number": "2", "scv/memory": "4000"}}}
+	nodes := []*v1.Node{testNode("one-card"), testNode("small-cards"), testNode("fits")}
+	y := newTestYoda(t, pod, nodes,
+		testScv("one-card", testCard(8000, 8000)),
+		testScv("small-cards", testCard(2000, 2000), testCard(2000, 2000)),
+		testScv("fits", testCard(8000, 8000), testCard(8000, 8000)))
+	state := framework.NewCycleState()
+	for i := 0; i < 2; i++ {
+		for _, node := range nodes {
+			nodeInfo, err := y.handle.SnapshotSharedLister().NodeInfos().Get(node.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			y.Filter(ctx, state, pod, nodeInfo)
+		}
+	}
+	want := map[string]uint64{"number": 2, "memory": 2}
+	if got := y.rejections.Counts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("rejections = %v, want %v", got, want)
+	}
+}
+
+func TestEffectiveArgsAfterNew(t *testing.T) {
+	// Outside a cluster no SCV client can be built, so start in passthrough.
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		t.Skip("running in a cluster, the SCV client can be constructed")
+	}
+	cs := fake.NewSimpleClientset()
+	handle := &fakeHandle{
+		clientSet: cs,
+		snapshot:  nodeinfosnapshot.NewSnapshot(nodeinfosnapshot.CreateNodeInfoMap(nil, nil)),
+		informers: informers.NewSharedInformerFactory(cs, 0),
+	}
+	config := &runtime.Unknown{Raw: []byte(`{"onScvClientFailure":"passthrough","maxDecisionRetries":7,"filterReadMode":"cache"}`)}
+	plugin, err := New(config, handle)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	y := plugin.(*Yoda)
+	got := y.EffectiveArgs()
+	if got.MaxDecisionRetries != 7 || got.FilterReadMode != string(scvcache.ReadCache) {
+		t.Errorf("configured args = %v retries, %q filter reads, want 7, %q", got.MaxDecisionRetries, got.FilterReadMode, scvcache.ReadCache)
+	}
+	if got.RejectionWindowMinutes != DefaultRejectionWindowMinutes || got.ScvCacheTTLSeconds != DefaultScvCacheTTLSeconds {
+		t.Errorf("defaulted args = %v minute window, %vs cache TTL, want %v, %v", got.RejectionWindowMinutes, got.ScvCacheTTLSeconds, DefaultRejectionWindowMinutes, DefaultScvCacheTTLSeconds)
+	}
+	if got.ScoreReadMode != string(scvcache.ReadLive) || got.ScvClientQPS != rest.DefaultQPS {
+		t.Errorf("defaulted args = %q score reads, %v QPS, want %q, %v", got.ScoreReadMode, got.ScvClientQPS, scvcache.ReadLive, rest.DefaultQPS)
+	}
+	y.SetMaintenanceMode(true)
+	if !y.EffectiveArgs().MaintenanceMode {
+		t.Error("EffectiveArgs() does not reflect maintenance mode set at runtime")
+	}
+}
+
+// filterAndScore runs one scheduling cycle of the pod over the named nodes and
+// returns which nodes pass Filter and the raw score of each, including nodes
+// Filter rejected.
+func filterAndScore(t *testing.T, y *Yoda, pod *v1.Pod, nodes ...string) (map[string]bool, map[string]int64) {
+	t.Helper()
+	ctx := context.Background()
+	state := framework.NewCycleState()
+	if status := y.PreFilter(ctx, state, pod); !status.IsSuccess() {
+		t.Fatalf("PreFilter() = %v", status.Message())
+	}
+	fits := map[string]bool{}
+	var feasible []*v1.Node
+	for _, name := range nodes {
+		nodeInfo, err := y.handle.SnapshotSharedLister().NodeInfos().Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fits[name] = y.Filter(ctx, state, pod, nodeInfo).IsSuccess(); fits[name] {
+			feasible = append(feasible, nodeInfo.Node())
+		}
+	}
+	if status := y.PostFilter(ctx, state, pod, feasible, nil); !status.IsSuccess() {
+		t.Fatalf("PostFilter() = %v", status.Message())
+	}
+	scores := map[string]int64{}
+	for _, name := range nodes {
+		score, status := y.Score(ctx, state, pod, name)
+		if !status.IsSuccess() {
+			t.Fatalf("Score(%v) = %v", name, status.Message())
+		}
+		scores[name] = score
+	}
+	return fits, scores
+}
+
+func TestScaleDownPlacement(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod", Labels: map[string]string{"scv/memory": "4000"}}}
+	leaving := testNode("leaving")
+	leaving.Spec.Taints = []v1.Taint{{Key: "ToBeDeletedByClusterAutoscaler", Effect: v1.TaintEffectNoSchedule}}
+	nodes := []*v1.Node{testNode("stable"), leaving}
+	tests := []struct {
+		name        string
+		respect     bool
+		wantLeaving bool
+	}{
+		{name: "penalized by default", wantLeaving: true},
+		{name: "excluded when scale-down is respected", respect: true, wantLeaving: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			y := newTestYoda(t, pod, nodes, testScv("stable", testCard(8000, 8000)), testScv("leaving", testCard(8000, 8000)))
+			y.args.RespectScaleDown = tt.respect
+			y.predicates = y.args.Predicates(y.ledger)
+
+			fits, scores := filterAndScore(t, y, pod, "stable", "leaving")
+			if !fits["stable"] || fits["leaving"] != tt.wantLeaving {
+				t.Errorf("Filter() passes %v, want stable and leaving %v", fits, tt.wantLeaving)
+			}
+			if scores["stable"] <= scores["leaving"] {
+				t.Errorf("Score() = %v, want the stable node ahead", scores)
+			}
+		})
+	}
+}
+
+func TestDrainPlacement(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod", Labels: map[string]string{"scv/memory": "4000"}}}
+	cordoned := testNode("cordoned")
+	cordoned.Spec.Unschedulable = true
+	annotated := testNode("annotated")
+	annotated.Annotations = map[string]string{filter.DrainAnnotation: "true"}
+	nodes := []*v1.Node{testNode("stable"), cordoned, annotated}
+	tests := []struct {
+		name         string
+		respect      string
+		wantDraining bool
+		wantPenalty  bool
+	}{
+		{name: "ignored by default", wantDraining: true},
+		{name: "penalized", respect: filter.RespectDrainPenalize, wantDraining: true, wantPenalty: true},
+		{name: "excluded", respect: filter.RespectDrainExclude, wantDraining: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			y := newTestYoda(t, pod, nodes, testScv("stable", testCard(8000, 8000)),
+				testScv("cordoned", testCard(8000, 8000)), testScv("annotated", testCard(8000, 8000)))
+			y.args.RespectDrain = tt.respect
+			y.predicates = y.args.Predicates(y.ledger)
+
+			fits, scores := filterAndScore(t, y, pod, "stable", "cordoned", "annotated")
+			for _, draining := range []string{"cordoned", "annotated"} {
+				if !fits["stable"] || fits[draining] != tt.wantDraining {
+					t.Errorf("Filter() passes %v, want stable and %v %v", fits, draining, tt.wantDraining)
+				}
+				if tt.wantPenalty && scores["stable"] <= scores[draining] {
+					t.Errorf("Score() = %v, want the stable node ahead of %v", scores, draining)
+				}
+				if !tt.wantPenalty && tt.wantDraining && scores["stable"] != scores[draining] {
+					t.Errorf("Score() = %v, want %v scored like the stable node", scores, draining)
+				}
+			}
+		})
+	}
+}
+
+func TestSysmemPlacement(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod",
+		Labels:      map[string]string{"scv/number": "2", "scv/memory": "4000"},
+		Annotations: map[string]string{filter.SysmemPerGpuAnnotation: "32"}}}
+	node := func(name, memory string) *v1.Node {
+		n := testNode(name)
+		n.Status.Allocatable = v1.ResourceList{v1.ResourceMemory: resource.MustParse(memory)}
+		return n
+	}
+	nodes := []*v1.Node{node("rich", "128Gi"), node("starved", "48Gi")}
+	tests := []struct {
+		mode        string
+		wantStarved bool
+	}{
+		{mode: filter.SysmemHard, wantStarved: false},
+		{mode: filter.SysmemSoft, wantStarved: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			y := newTestYoda(t, pod, nodes,
+				testScv("rich", testCard(8000, 8000), testCard(8000, 8000)), testScv("starved", testCard(8000, 8000), testCard(8000, 8000)))
+			y.args.SysmemPerGpuMode = tt.mode
+			y.predicates = y.args.Predicates(y.ledger)
+
+			fits, scores := filterAndScore(t, y, pod, "rich", "starved")
+			if !fits["rich"] || fits["starved"] != tt.wantStarved {
+				t.Errorf("Filter() passes %v, want rich and starved %v", fits, tt.wantStarved)
+			}
+			if tt.mode == filter.SysmemSoft && scores["rich"] <= scores["starved"] {
+				t.Errorf("Score() = %v, want the rich node ahead", scores)
+			}
+		})
+	}
+}
+
+func TestColdSparePlacement(t *testing.T) {
+	tests := []struct {
+		name      string
+		memory    string
+		wantBusy  bool
+		wantSpare bool
+	}{
+		{name: "small job avoids the cold spare", memory: "4000", wantBusy: true, wantSpare: true},
+		{name: "job fitting only the cold spare is placed there", memory: "12000", wantBusy: false, wantSpare: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod", Labels: map[string]string{"scv/memory": tt.memory}}}
+			nodes := []*v1.Node{testNode("busy"), testNode("spare")}
+			y := newTestYoda(t, pod, nodes, testScv("busy", testCard(8000, 12000)), testScv("spare", testCard(16000, 16000)))
+			y.args.ColdSpareCount = 1
+
+			fits, scores := filterAndScore(t, y, pod, "busy", "spare")
+			if fits["busy"] != tt.wantBusy || fits["spare"] != tt.wantSpare {
+				t.Fatalf("Filter() passes %v, want busy %v and spare %v", fits, tt.wantBusy, tt.wantSpare)
+			}
+			if tt.wantBusy && scores["busy"] <= scores["spare"] {
+				t.Errorf("Score() = %v, want the busy node ahead of the cold spare", scores)
+			}
+			// Without a cold spare the emptier node wins.
+			y.args.ColdSpareCount = 0
+			if _, scores := filterAndScore(t, y, pod, "busy", "spare"); tt.wantBusy && scores["spare"] <= scores["busy"] {
+				t.Errorf("Score() without cold spares = %v, want the spare node ahead", scores)
+			}
+		})
+	}
+}
+
+func TestPredicateSeverity(t *testing.T) {
+	metrics.Register()
+	tests := []struct {
+		severity     string
+		wantFits     bool
+		wantWarnings float64
+	}{
+		{severity: filter.SeverityReject, wantFits: false, wantWarnings: 0},
+		{severity: filter.SeverityWarn, wantFits: true, wantWarnings: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.severity, func(t *testing.T) {
+			ctx := context.Background()
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod", Labels: map[string]string{"scv/memory": "4000"}}}
+			old := testScv("node", testCard(8000, 8000))
+			old.Annotations = map[string]string{filter.AgentVersionAnnotation: "1.0.0"}
+			y := newTestYoda(t, pod, []*v1.Node{testNode("node")}, old)
+			y.args.MinScvAgentVersion = "1.2.0"
+			y.args.PredicateSeverity = map[string]string{"agent-version": tt.severity}
+			y.predicates = y.args.Predicates(y.ledger)
+
+			nodeInfo, err := y.handle.SnapshotSharedLister().NodeInfos().Get("node")
+			if err != nil {
+				t.Fatal(err)
+			}
+			before := counterValue(t, "predicate_warnings_total", "predicate", "agent-version")
+			if status := y.Filter(ctx, framework.NewCycleState(), pod, nodeInfo); status.IsSuccess() != tt.wantFits {
+				t.Errorf("Filter() = %v, want success %v", status.Message(), tt.wantFits)
+			}
+			if got := counterValue(t, "predicate_warnings_total", "predicate", "agent-version") - before; got != tt.wantWarnings {
+				t.Errorf("agent-version warnings grew by %v, want %v", got, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestIdleNodeConsolidation(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod", Labels: map[string]string{"scv/memory": "4000"}}}
+	nodes := []*v1.Node{testNode("partial"), testNode("idle")}
+	y := newTestYoda(t, pod, nodes, testScv("partial", testCard(6000, 12000)), testScv("idle", testCard(12000, 12000)))
+	if _, scores := filterAndScore(t, y, pod, "partial", "idle"); scores["idle"] <= scores["partial"] {
+		t.Errorf("Score() without consolidation = %v, want the idle node ahead", scores)
+	}
+	y.args.PreferConsolidation = true
+	y.args.IdleNodePenaltyWeight = 10
+	if _, scores := filterAndScore(t, y, pod, "partial", "idle"); scores["partial"] <= scores["idle"] {
+		t.Errorf("Score() with consolidation = %v, want the partially used node ahead", scores)
+	}
+}
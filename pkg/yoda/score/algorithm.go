@@ -2,9 +2,14 @@ package score
 
 import (
 	"errors"
+	"sort"
+	"time"
+
 	scv "github.com/NJUPT-ISL/SCV/api/v1"
 	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/collection"
 	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/ledger"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/stats"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog"
 	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
@@ -22,40 +27,201 @@ const (
 	FreeMemoryWeight  = 2
 	TotalMemoryWeight = 1
 	ActualWeight      = 2
+	ModelCopiesWeight = 2
+	ElasticWeight     = 2
 
 	AllocateWeight = 2
+
+	ScaleDownPenalty = 1000
+	DrainPenalty     = 1000
+	SysmemPenalty    = 1000
+	ColdSparePenalty = 2000
+	Binpack2DWeight  = 7
+)
+
+const (
+	StrategyDefault   = ""
+	StrategyBinpack2D = "binpack-2d"
+
+	AggregationMax  = "max"
+	AggregationMean = "mean"
+	AggregationTopK = "topk"
 )
 
-func CalculateScore(s *scv.Scv, state *framework.CycleState, pod *v1.Pod, info *nodeinfo.NodeInfo) (uint64, error) {
+type Options struct {
+	HomogeneityWeight uint64
+	// CardAggregation reduces the scores of a node's candidate cards to one value.
+	CardAggregation string
+	// CardTopK is the k used by topk aggregation, zero means the requested card number.
+	CardTopK int
+	// DeadlineUrgencyWeight scales the startup score by how close the pod is to its deadline.
+	DeadlineUrgencyWeight uint64
+	DeadlineHorizon       time.Duration
+	Now                   time.Time
+	PowerStateWeight      uint64
+	PowerSaving           bool
+	ArchMatchWeight       uint64
+	// BigNodeReservationWeight keeps small requests off nodes with many cards.
+	BigNodeReservationWeight uint64
+	// NeutralScore replaces score terms that come out as NaN or infinite.
+	NeutralScore float64
+	Strategy     string
+	Ledger       *ledger.Ledger
+	// IgnoredMetrics are card metrics the pod asked to be scored without.
+	IgnoredMetrics             IgnoredMetrics
+	ResidentModelWeight        uint64
+	CheckpointAffinityWeight   uint64
+	CpuRatioWeight             uint64
+	BandwidthContentionWeight  uint64
+	AttestationWeight          uint64
+	ThermalCurve               ThermalCurve
+	RecentlyFreedWeight        uint64
+	PlacementHistoryWeight     uint64
+	History                    *stats.PlacementHistory
+	PcieSwitchWeight           uint64
+	IdleNodePenaltyWeight      uint64
+	ModeMatchWeight            uint64
+	SidecarWarmWeight          uint64
+	VendorBalanceWeight        uint64
+	ClockUniformityWeight      uint64
+	RespectDrain               string
+	KernelErrorWeight          uint64
+	MigRepartitionWeight       uint64
+	InteractiveIsolationWeight uint64
+	FirstConsumerPenalty       uint64
+	ClockLockWeight            uint64
+	ExternalScoreWeight        uint64
+	MpsReuseWeight             uint64
+	SysmemPerGpuMode           string
+	// MissingDataPolicy decides how optional terms score on nodes without their data.
+	MissingDataPolicy string
+	// MaxBonusContribution caps the sum of the bonus terms, zero means no cap.
+	MaxBonusContribution float64
+}
+
+func CalculateScore(opts Options, s *scv.Scv, state *framework.CycleState, pod *v1.Pod, info *nodeinfo.NodeInfo) (int64, error) {
+	b, err := CalculateBreakdown(opts, s, state, pod, info)
+	if err != nil {
+		return 0, err
+	}
+	return ToNodeScore(Sanitize(b, opts.NeutralScore, info.Node().GetName()).Total()), nil
+}
+
+func CalculateBreakdown(opts Options, s *scv.Scv, state *framework.CycleState, pod *v1.Pod, info *nodeinfo.NodeInfo) (Breakdown, error) {
 	d, err := state.Read("Max")
 	if err != nil {
 		klog.V(3).Infof("Error Get CycleState Info: %v", err)
-		return 0, err
+		return nil, err
 	}
 	data, ok := d.(*collection.Data)
 	if !ok {
-		return 0, errors.New("The Type is not Data ")
+		return nil, errors.New("The Type is not Data ")
+	}
+	node := info.Node()
+	var b Breakdown
+	switch opts.Strategy {
+	case StrategyBinpack2D:
+		b = Breakdown{
+			{Name: "binpack-2d", Score: float64(CalculateBinpack2DScore(opts, s, pod, node.GetName()) * Binpack2DWeight)},
+		}
+	default:
+		b = Breakdown{
+			{Name: "basic", Score: float64(CalculateBasicScore(opts, data.Value, s, pod, node))},
+			{Name: "allocate", Score: float64(CalculateAllocateScore(info, s))},
+			{Name: "actual", Score: float64(CalculateActualScore(s))},
+		}
 	}
-	return CalculateBasicScore(data.Value, s, pod) + CalculateAllocateScore(info, s) + CalculateActualScore(s), nil
+	b = append(b, Breakdown{
+		{Name: "model-copies", Score: float64(CalculateModelCopiesScore(data.Value, s, pod))},
+		{Name: "homogeneity", Score: float64(CalculateHomogeneityScore(s, pod) * opts.HomogeneityWeight)},
+		{Name: "urgency", Score: float64(CalculateUrgencyScore(opts, pod, node))},
+		{Name: "power-state", Score: float64(CalculatePowerStateScore(opts, s, pod, node))},
+		{Name: "arch", Score: CalculateArchScore(opts, s, pod, node)},
+		{Name: "node-size", Score: float64(CalculateNodeSizeScore(s, pod) * opts.BigNodeReservationWeight)},
+		{Name: "scale-down", Score: CalculateScaleDownScore(node)},
+		{Name: "drain", Score: CalculateDrainScore(opts, node)},
+		{Name: "sysmem", Score: CalculateSysmemScore(opts, s, pod, node)},
+		{Name: "resident-model", Score: float64(CalculateResidentModelScore(opts, s, pod, node))},
+		{Name: "cold-spare", Score: CalculateColdSpareScore(state, node.GetName())},
+		{Name: "idle-node", Score: CalculateIdleNodeScore(opts, state, s)},
+		{Name: "first-consumer", Score: CalculateFirstConsumerScore(opts, s)},
+		{Name: "checkpoint", Score: float64(CalculateCheckpointScore(opts, s, pod, info))},
+		{Name: "cpu-ratio", Score: CalculateCpuRatioScore(opts, s, pod, info)},
+		{Name: "elastic", Score: float64(CalculateElasticScore(opts, s, pod))},
+		{Name: "attestation", Score: float64(CalculateAttestationScore(opts, pod, node))},
+		{Name: "recently-freed", Score: float64(CalculateRecentlyFreedScore(opts, s, pod))},
+		{Name: "placement-history", Score: float64(CalculatePlacementHistoryScore(opts, pod, node.GetName()))},
+		{Name: "pcie-switch", Score: float64(CalculatePcieSwitchScore(opts, s, pod, node))},
+		{Name: "mode-match", Score: CalculateModeMatchScore(opts, s, pod, node)},
+		{Name: "mps-reuse", Score: float64(CalculateMpsReuseScore(opts, s, pod, node))},
+		{Name: "mig-repartition", Score: CalculateMigRepartitionScore(opts, s, pod, node)},
+		{Name: "sidecar-warm", Score: float64(CalculateSidecarWarmScore(opts, pod, info))},
+		{Name: "clock-lock", Score: float64(CalculateClockLockScore(opts, s, pod, node))},
+		{Name: "clock-uniformity", Score: float64(CalculateClockUniformityScore(opts, s, pod))},
+		{Name: "kernel-errors", Score: CalculateKernelErrorScore(opts, node)},
+		{Name: "vendor-balance", Score: float64(CalculateVendorBalanceScore(opts, state, pod, node))},
+		{Name: "interactive-isolation", Score: float64(CalculateInteractiveIsolationScore(opts, s, pod, node.GetName()))},
+		{Name: "external", Score: CalculateExternalScore(opts, node)},
+		{Name: "bandwidth-contention", Score: float64(CalculateBandwidthContentionScore(opts, s, pod, node.GetName()))},
+	}...)
+	return CapBonuses(ApplyMissingDataPolicy(opts, state, pod, node, b), opts.MaxBonusContribution), nil
 }
 
-func CalculateBasicScore(value collection.MaxValue, scv *scv.Scv, pod *v1.Pod) uint64 {
-	var cardScore uint64
-	if ok, number := filter.PodFitsNumber(pod, scv); ok {
+func CalculateBasicScore(opts Options, value collection.MaxValue, scv *scv.Scv, pod *v1.Pod, node *v1.Node) uint64 {
+	var cardScores []uint64
+	ok, number := filter.PodFitsNumber(pod, scv)
+	if ok {
 		isFitsMemory, memory := filter.PodFitsMemory(number, pod, scv)
 		isFitsClock, clock := filter.PodFitsClock(number, pod, scv)
 		if isFitsClock && isFitsMemory {
-			for _, card := range scv.Status.CardList {
+			for i, card := range scv.Status.CardList {
 				if card.FreeMemory >= memory && card.Clock >= clock {
-					cardScore += CalculateCardScore(value, card)
+					cardScore := CalculateCardScore(value, card, opts.IgnoredMetrics) * ThermalDerate(opts, pod, node, i) / 100
+					cardScores = append(cardScores, cardScore)
 				}
 			}
 		}
 	}
-	return cardScore
+	k := opts.CardTopK
+	if k <= 0 {
+		k = int(number)
+	}
+	return AggregateCardScores(cardScores, opts.CardAggregation, k)
+}
+
+func AggregateCardScores(scores []uint64, aggregation string, k int) uint64 {
+	if len(scores) == 0 {
+		return 0
+	}
+	sorted := append([]uint64(nil), scores...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] > sorted[j]
+	})
+	switch aggregation {
+	case AggregationMean:
+		return mean(sorted)
+	case AggregationTopK:
+		if k < 1 {
+			k = 1
+		}
+		if k > len(sorted) {
+			k = len(sorted)
+		}
+		return mean(sorted[:k])
+	default:
+		return sorted[0]
+	}
 }
 
-func CalculateCardScore(value collection.MaxValue, card scv.Card) uint64 {
+func mean(scores []uint64) uint64 {
+	var sum uint64
+	for _, s := range scores {
+		sum += s
+	}
+	return sum / uint64(len(scores))
+}
+
+func CalculateCardScore(value collection.MaxValue, card scv.Card, ignored IgnoredMetrics) uint64 {
 	var (
 		bandwidth   = card.Bandwidth * 100 / value.MaxBandwidth
 		clock       = card.Clock * 100 / value.MaxBandwidth
@@ -64,23 +230,167 @@ func CalculateCardScore(value collection.MaxValue, card scv.Card) uint64 {
 		freeMemory  = card.FreeMemory * 100 / value.MaxFreeMemory
 		totalMemory = card.TotalMemory * 100 / value.MaxTotalMemory
 	)
+	if ignored[MetricBandwidth] {
+		bandwidth = 0
+	}
+	if ignored[MetricClock] {
+		clock = 0
+	}
+	if ignored[MetricCore] {
+		core = 0
+	}
+	if ignored[MetricPower] {
+		power = 0
+	}
+	if ignored[MetricFreeMemory] {
+		freeMemory = 0
+	}
+	if ignored[MetricTotalMemory] {
+		totalMemory = 0
+	}
 	return uint64(bandwidth*BandwidthWeight+clock*ClockWeight+core*CoreWeight+power*PowerWeight) +
 		freeMemory*FreeMemoryWeight + totalMemory*TotalMemoryWeight
 }
 
 func CalculateActualScore(scv *scv.Scv) uint64 {
+	if scv.Status.TotalMemorySum == 0 {
+		return 0
+	}
 	return (scv.Status.FreeMemorySum * 100 / scv.Status.TotalMemorySum) * ActualWeight
 }
 
+// CalculateModelCopiesScore prefers the card that can hold the most additional
+// model copies after placement, relative to the best card in the cycle.
+func CalculateModelCopiesScore(value collection.MaxValue, scv *scv.Scv, pod *v1.Pod) uint64 {
+	size, copies, ok := filter.PodModelCopies(pod)
+	if !ok || size == 0 || value.MaxFreeMemory < size*copies {
+		return 0
+	}
+	most := (value.MaxFreeMemory - size*copies) / size
+	if most == 0 {
+		return 0
+	}
+	var best uint64
+	for _, card := range scv.Status.CardList {
+		if !filter.CardFitsMemory(size*copies, card) {
+			continue
+		}
+		if extra := (card.FreeMemory - size*copies) / size; extra > best {
+			best = extra
+		}
+	}
+	if best > most {
+		best = most
+	}
+	return best * 100 / most * ModelCopiesWeight
+}
+
+// CalculateHomogeneityScore rewards nodes that can serve a multi-card request
+// with cards of a single model, scaled down as the placement gets more mixed.
+func CalculateHomogeneityScore(scv *scv.Scv, pod *v1.Pod) uint64 {
+	ok, number := filter.PodFitsNumber(pod, scv)
+	if !ok || number < 2 {
+		return 0
+	}
+	_, memory := filter.PodFitsMemory(number, pod, scv)
+	_, clock := filter.PodFitsClock(number, pod, scv)
+	var (
+		models = map[string]uint{}
+		best   uint
+	)
+	for _, card := range scv.Status.CardList {
+		if !filter.CardFits(memory, clock, card) {
+			continue
+		}
+		models[card.Model]++
+		if models[card.Model] > best {
+			best = models[card.Model]
+		}
+	}
+	if best >= number {
+		return 100
+	}
+	return uint64(best * 100 / number)
+}
+
+// CalculateNodeSizeScore is the share of the node's cards the request would use,
+// so small requests avoid stranding big nodes that large jobs need.
+func CalculateNodeSizeScore(scv *scv.Scv, pod *v1.Pod) uint64 {
+	_, number := filter.PodFitsNumber(pod, scv)
+	total := scv.Status.CardNumber
+	if total == 0 {
+		return 0
+	}
+	if number > total {
+		number = total
+	}
+	return uint64(number * 100 / total)
+}
+
+// CalculateScaleDownScore steers pods away from nodes the autoscaler wants to remove.
+func CalculateScaleDownScore(node *v1.Node) float64 {
+	if filter.NodeMarkedForScaleDown(node) {
+		return -ScaleDownPenalty
+	}
+	return 0
+}
+
+// CalculateDrainScore steers pods away from nodes an operator is draining.
+func CalculateDrainScore(opts Options, node *v1.Node) float64 {
+	if opts.RespectDrain == filter.RespectDrainPenalize && filter.NodeDraining(node) {
+		return -DrainPenalty
+	}
+	return 0
+}
+
+// CalculateFirstConsumerScore penalizes being the first GPU pod on a node,
+// so pods fill nodes that are already awake before waking idle ones.
+func CalculateFirstConsumerScore(opts Options, s *scv.Scv) float64 {
+	if opts.FirstConsumerPenalty == 0 || opts.Ledger == nil || !collection.NodeIsIdle(s, opts.Ledger) {
+		return 0
+	}
+	return -float64(opts.FirstConsumerPenalty)
+}
+
+// CalculateSysmemScore penalizes nodes short of host memory for the pod's
+// cards in proportion to the shortfall.
+func CalculateSysmemScore(opts Options, s *scv.Scv, pod *v1.Pod, node *v1.Node) float64 {
+	if opts.SysmemPerGpuMode != filter.SysmemSoft {
+		return 0
+	}
+	return -SysmemPenalty * filter.SysmemShortfall(pod, node, s)
+}
+
+// CalculateIdleNodeScore penalizes waking a fully idle node while a node
+// already in use can absorb the pod.
+func CalculateIdleNodeScore(opts Options, state *framework.CycleState, s *scv.Scv) float64 {
+	if opts.IdleNodePenaltyWeight == 0 || opts.Ledger == nil {
+		return 0
+	}
+	c := collection.ReadConsolidation(state)
+	if c == nil || !c.Absorbable || !collection.NodeIsIdle(s, opts.Ledger) {
+		return 0
+	}
+	return -100 * float64(opts.IdleNodePenaltyWeight)
+}
+
+// CalculateColdSpareScore keeps cold spare nodes empty unless nothing else fits.
+func CalculateColdSpareScore(state *framework.CycleState, nodeName string) float64 {
+	if collection.ReadColdSpares(state)[nodeName] {
+		return -ColdSparePenalty
+	}
+	return 0
+}
+
 func CalculateAllocateScore(info *nodeinfo.NodeInfo, scv *scv.Scv) uint64 {
 	allocateMemorySum := uint64(0)
 	for _, pod := range info.Pods() {
-		if mem, ok := pod.GetLabels()["scv/memory"]; ok {
-			allocateMemorySum += filter.StrToUint64(mem)
+		if mem, ok := filter.PodMemory(pod, scv); ok {
+			allocateMemorySum += mem
 		}
 	}
 
-	if scv.Status.TotalMemorySum < allocateMemorySum {
+	if scv.Status.TotalMemorySum == 0 || scv.Status.TotalMemorySum < allocateMemorySum {
 		return 0
 	}
 
@@ -0,0 +1,681 @@
+package score
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/collection"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/ledger"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/stats"
+)
+
+// scoreTotals returns each node's raw score for the pod after collecting the
+// cycle's max values into state, which may be nil. pods lists the pods
+// already running on each node.
+func scoreTotals(t *testing.T, opts Options, state *framework.CycleState, pod *v1.Pod, nodes []SelfTestNode, pods map[string][]*v1.Pod) map[string]float64 {
+	t.Helper()
+	if state == nil {
+		state = framework.NewCycleState()
+	}
+	list := scv.ScvList{}
+	for _, n := range nodes {
+		list.Items = append(list.Items, n.Scv)
+	}
+	if status := collection.CollectMaxValues(state, pod, list); !status.IsSuccess() {
+		t.Fatal(status.Message())
+	}
+	totals := map[string]float64{}
+	for i := range nodes {
+		n := nodes[i]
+		b, err := CalculateBreakdown(opts, &n.Scv, state, pod, nodeInfo(t, n.Node, pods[n.Node.Name]...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		totals[n.Node.Name] = b.Total()
+	}
+	return totals
+}
+
+func expectPreferred(t *testing.T, totals map[string]float64, better, worse string) {
+	t.Helper()
+	if totals[better] <= totals[worse] {
+		t.Errorf("node %v scores %v, want it above node %v at %v", better, totals[better], worse, totals[worse])
+	}
+}
+
+func withAnnotations(n SelfTestNode, annotations map[string]string) SelfTestNode {
+	n.Node.Annotations = annotations
+	return n
+}
+
+func nodeInfo(t *testing.T, node *v1.Node, pods ...*v1.Pod) *nodeinfo.NodeInfo {
+	t.Helper()
+	info := nodeinfo.NewNodeInfo(pods...)
+	if err := info.SetNode(node); err != nil {
+		t.Fatal(err)
+	}
+	return info
+}
+
+func modelCard(model string) scv.Card {
+	card := goldenCard(10000, 12000, 1500)
+	card.Model = model
+	return card
+}
+
+func TestCalculateModelCopiesScore(t *testing.T) {
+	pod := goldenPod(nil)
+	pod.Annotations = map[string]string{filter.ModelSizeAnnotation: "4GB"}
+	// The 80GB card has room for ten more 4GB copies, the 24GB card for three.
+	big := goldenNode("big", goldenCard(44*1024, 80*1024, 1500)).Scv
+	small := goldenNode("small", goldenCard(16*1024, 24*1024, 1500)).Scv
+	full := goldenNode("full", goldenCard(4*1024, 24*1024, 1500)).Scv
+	value := collection.MaxValue{MaxFreeMemory: 44 * 1024}
+	tests := []struct {
+		name string
+		scv  scv.Scv
+		want uint64
+	}{
+		{name: "most extra copies", scv: big, want: 100 * ModelCopiesWeight},
+		{name: "fewer extra copies", scv: small, want: 30 * ModelCopiesWeight},
+		{name: "no extra copies", scv: full, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalculateModelCopiesScore(value, &tt.scv, pod); got != tt.want {
+				t.Errorf("CalculateModelCopiesScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateBasicScoreAggregation(t *testing.T) {
+	pod := goldenPod(map[string]string{"scv/memory": "1000"})
+	node := goldenNode("node", goldenCard(10000, 12000, 1500), goldenCard(6000, 12000, 1500), goldenCard(2000, 12000, 1500))
+	value := collection.MaxValue{MaxBandwidth: 1, MaxClock: 1, MaxCore: 1, MaxFreeMemory: 10000, MaxPower: 1, MaxTotalMemory: 1}
+	// Only free memory counts, so the cards score 200, 120 and 40.
+	ignored := IgnoredMetrics{MetricBandwidth: true, MetricClock: true, MetricCore: true, MetricPower: true, MetricTotalMemory: true}
+	tests := []struct {
+		name        string
+		aggregation string
+		k           int
+		want        uint64
+	}{
+		{name: "max takes the best card", aggregation: AggregationMax, want: 200},
+		{name: "mean averages every card", aggregation: AggregationMean, want: 120},
+		{name: "topk averages the best k", aggregation: AggregationTopK, k: 2, want: 160},
+		{name: "topk beyond the card count", aggregation: AggregationTopK, k: 5, want: 120},
+		{name: "topk defaults to the requested number", aggregation: AggregationTopK, want: 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{CardAggregation: tt.aggregation, CardTopK: tt.k, IgnoredMetrics: ignored}
+			if got := CalculateBasicScore(opts, value, &node.Scv, pod, node.Node); got != tt.want {
+				t.Errorf("CalculateBasicScore() with k=%v = %v, want %v", tt.k, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHomogeneityRanking(t *testing.T) {
+	pod := goldenPod(map[string]string{"scv/number": "2", "scv/memory": "4000"})
+	nodes := []SelfTestNode{
+		goldenNode("same", modelCard("A100"), modelCard("A100")),
+		goldenNode("mixed", modelCard("A100"), modelCard("V100")),
+	}
+	totals := scoreTotals(t, Options{HomogeneityWeight: 1}, nil, pod, nodes, nil)
+	expectPreferred(t, totals, "same", "mixed")
+	if totals := scoreTotals(t, Options{}, nil, pod, nodes, nil); totals["same"] != totals["mixed"] {
+		t.Errorf("without a weight the nodes score %v, want them equal", totals)
+	}
+}
+
+func TestUrgencyRanking(t *testing.T) {
+	fake := clock.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	pod := goldenPod(map[string]string{"scv/memory": "4000"})
+	pod.Annotations = map[string]string{DeadlineAnnotation: fake.Now().Add(2 * time.Hour).Format(time.RFC3339)}
+	pod.Spec.Containers = []v1.Container{{Name: "train", Image: "train:v1"}}
+	// roomy has the better card, warm already has the pod's image.
+	warm := goldenNode("warm", goldenCard(8000, 12000, 1500))
+	warm.Node.Status.Images = []v1.ContainerImage{{Names: []string{"train:v1"}}}
+	nodes := []SelfTestNode{goldenNode("roomy", goldenCard(12000, 12000, 1500)), warm}
+	opts := Options{DeadlineUrgencyWeight: 2, DeadlineHorizon: 2 * time.Hour}
+
+	var lead []float64
+	for i := 0; i <= 4; i++ {
+		opts.Now = fake.Now()
+		totals := scoreTotals(t, opts, nil, pod, nodes, nil)
+		lead = append(lead, totals["warm"]-totals["roomy"])
+		fake.Step(30 * time.Minute)
+	}
+	if lead[0] >= 0 {
+		t.Errorf("two hours out the warm node leads by %v, want the roomy node ahead", lead[0])
+	}
+	if lead[4] <= 0 {
+		t.Errorf("at the deadline the warm node leads by %v, want it ahead", lead[4])
+	}
+	for i := 1; i < len(lead); i++ {
+		if lead[i] <= lead[i-1] {
+			t.Errorf("warm node lead %v does not grow as the deadline nears", lead)
+			break
+		}
+	}
+}
+
+func TestPowerStateRanking(t *testing.T) {
+	card := goldenCard(8000, 12000, 1500)
+	nodes := []SelfTestNode{
+		withAnnotations(goldenNode("active", card), map[string]string{PowerStateAnnotation: PowerStateActive}),
+		withAnnotations(goldenNode("idle", card), map[string]string{PowerStateAnnotation: PowerStateIdle}),
+	}
+	latency := goldenPod(map[string]string{"scv/memory": "4000"})
+	latency.Annotations = map[string]string{LatencySensitiveAnnotation: "true"}
+	batch := goldenPod(map[string]string{"scv/memory": "4000"})
+
+	opts := Options{PowerStateWeight: 1, PowerSaving: true}
+	expectPreferred(t, scoreTotals(t, opts, nil, latency, nodes, nil), "active", "idle")
+	expectPreferred(t, scoreTotals(t, opts, nil, batch, nodes, nil), "idle", "active")
+	opts.PowerSaving = false
+	if totals := scoreTotals(t, opts, nil, batch, nodes, nil); totals["active"] != totals["idle"] {
+		t.Errorf("without power saving a batch pod scores the nodes %v, want them equal", totals)
+	}
+}
+
+func TestArchRanking(t *testing.T) {
+	pod := goldenPod(map[string]string{"scv/memory": "4000"})
+	pod.Annotations = map[string]string{TargetArchAnnotation: "sm_80"}
+	card := goldenCard(8000, 12000, 1500)
+	archNode := func(name, arch string) SelfTestNode {
+		return withAnnotations(goldenNode(name, card), map[string]string{CardArchAnnotation: arch})
+	}
+	nodes := []SelfTestNode{archNode("exact", "sm_80"), archNode("compatible", "sm_86"), archNode("jit", "sm_75")}
+	opts := Options{ArchMatchWeight: 1}
+	totals := scoreTotals(t, opts, nil, pod, nodes, nil)
+	expectPreferred(t, totals, "exact", "compatible")
+	expectPreferred(t, totals, "compatible", "jit")
+	if got := CalculateArchScore(opts, &nodes[2].Scv, pod, nodes[2].Node); got != ArchJitScore {
+		t.Errorf("CalculateArchScore() for a JIT-required card = %v, want the %v penalty", got, ArchJitScore)
+	}
+	if got := CalculateArchScore(opts, &nodes[2].Scv, pod, goldenNode("unknown", card).Node); got != 0 {
+		t.Errorf("CalculateArchScore() for an unknown architecture = %v, want 0", got)
+	}
+}
+
+func TestNodeSizeRanking(t *testing.T) {
+	cards := func(n int) []scv.Card {
+		var list []scv.Card
+		for i := 0; i < n; i++ {
+			list = append(list, goldenCard(8000, 12000, 1500))
+		}
+		return list
+	}
+	nodes := []SelfTestNode{goldenNode("small", cards(2)...), goldenNode("big", cards(8)...)}
+	opts := Options{BigNodeReservationWeight: 1}
+	single := goldenPod(map[string]string{"scv/number": "1"})
+	expectPreferred(t, scoreTotals(t, opts, nil, single, nodes, nil), "small", "big")
+	if totals := scoreTotals(t, Options{}, nil, single, nodes, nil); totals["small"] != totals["big"] {
+		t.Errorf("without a weight the nodes score %v, want them equal", totals)
+	}
+	large := goldenPod(map[string]string{"scv/number": "6"})
+	expectPreferred(t, scoreTotals(t, opts, nil, large, nodes, nil), "big", "small")
+}
+
+func TestBinpack2DRanking(t *testing.T) {
+	pod := goldenPod(map[string]string{"scv/memory": "4000"})
+	pod.Annotations = map[string]string{filter.ComputeAnnotation: "50"}
+	// After placement balanced uses two thirds of its memory and 70% of its
+	// compute, exhausted all of its memory but only half its compute.
+	l := ledger.New()
+	l.Reserve("tenant", ledger.Reservation{Node: "balanced", Cards: []int{0}, Compute: 20})
+	nodes := []SelfTestNode{
+		goldenNode("balanced", goldenCard(8000, 12000, 1500)),
+		goldenNode("exhausted", goldenCard(4000, 12000, 1500)),
+	}
+	opts := Options{Strategy: StrategyBinpack2D, Ledger: l}
+	expectPreferred(t, scoreTotals(t, opts, nil, pod, nodes, nil), "balanced", "exhausted")
+}
+
+func TestIgnoredMetricsRanking(t *testing.T) {
+	curve, err := ParseThermalCurve("70:100,90:60")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{ClockUniformityWeight: 1, ClockLockWeight: 1, ThermalCurve: curve}
+	pod := goldenPod(map[string]string{"scv/number": "2"})
+	pod.Annotations = map[string]string{filter.LockClocksAnnotation: "true", filter.BandwidthHeavyAnnotation: "true"}
+	tests := []struct {
+		name   string
+		metric string
+		nodes  []SelfTestNode
+	}{
+		{
+			name:   "clock",
+			metric: MetricClock,
+			nodes: []SelfTestNode{
+				withAnnotations(goldenNode("fast", goldenCard(8000, 12000, 1800), goldenCard(8000, 12000, 1800)),
+					map[string]string{filter.CardLockedClockAnnotation: "1800,1800"}),
+				goldenNode("slow", goldenCard(8000, 12000, 1500), goldenCard(8000, 12000, 1200)),
+			},
+		},
+		{
+			name:   "temperature",
+			metric: MetricTemperature,
+			nodes: []SelfTestNode{
+				withAnnotations(goldenNode("fast", goldenCard(8000, 12000, 1500), goldenCard(8000, 12000, 1500)),
+					map[string]string{CardTemperatureAnnotation: "60,60"}),
+				withAnnotations(goldenNode("slow", goldenCard(8000, 12000, 1500), goldenCard(8000, 12000, 1500)),
+					map[string]string{CardTemperatureAnnotation: "85,85"}),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts.IgnoredMetrics = nil
+			expectPreferred(t, scoreTotals(t, opts, nil, pod, tt.nodes, nil), "fast", "slow")
+			opts.IgnoredMetrics = IgnoredMetrics{tt.metric: true}
+			if totals := scoreTotals(t, opts, nil, pod, tt.nodes, nil); totals["fast"] != totals["slow"] {
+				t.Errorf("ignoring %v the nodes score %v, want them equal", tt.metric, totals)
+			}
+		})
+	}
+}
+
+func TestResidentModelRanking(t *testing.T) {
+	card := goldenCard(8000, 12000, 1500)
+	nodes := []SelfTestNode{
+		withAnnotations(goldenNode("resident", card), map[string]string{ResidentModelsAnnotation: "bert;llama"}),
+		withAnnotations(goldenNode("cold", card), map[string]string{ResidentModelsAnnotation: "gpt2"}),
+	}
+	opts := Options{ResidentModelWeight: 1}
+	pod := goldenPod(map[string]string{"scv/memory": "4000"})
+	pod.Annotations = map[string]string{ModelNameAnnotation: "llama"}
+	expectPreferred(t, scoreTotals(t, opts, nil, pod, nodes, nil), "resident", "cold")
+	pod.Annotations = nil
+	if totals := scoreTotals(t, opts, nil, pod, nodes, nil); totals["resident"] != totals["cold"] {
+		t.Errorf("without a model name the nodes score %v, want them equal", totals)
+	}
+}
+
+func TestCheckpointRanking(t *testing.T) {
+	occupant := func(name, progress string) *v1.Pod {
+		p := goldenPod(nil)
+		p.Name = name
+		p.Annotations = map[string]string{filter.CardsAnnotation: "0", CheckpointProgressAnnotation: progress}
+		return p
+	}
+	card := goldenCard(8000, 12000, 1500)
+	nodes := []SelfTestNode{goldenNode("near", card), goldenNode("fresh", card)}
+	pods := map[string][]*v1.Pod{
+		"near":  {occupant("near-checkpoint", "90")},
+		"fresh": {occupant("just-started", "5")},
+	}
+	pod := goldenPod(map[string]string{"scv/memory": "4000"})
+	expectPreferred(t, scoreTotals(t, Options{CheckpointAffinityWeight: 1}, nil, pod, nodes, pods), "near", "fresh")
+	if totals := scoreTotals(t, Options{}, nil, pod, nodes, pods); totals["near"] != totals["fresh"] {
+		t.Errorf("without a weight the nodes score %v, want them equal", totals)
+	}
+}
+
+func TestBandwidthContentionRanking(t *testing.T) {
+	l := ledger.New()
+	l.Reserve("light", ledger.Reservation{Node: "calm", Cards: []int{0}})
+	l.Reserve("heavy-1", ledger.Reservation{Node: "saturated", Cards: []int{0}, BandwidthHeavy: true})
+	l.Reserve("heavy-2", ledger.Reservation{Node: "saturated", Cards: []int{0}, BandwidthHeavy: true})
+	card := goldenCard(8000, 12000, 1500)
+	nodes := []SelfTestNode{goldenNode("calm", card), goldenNode("saturated", card)}
+	opts := Options{BandwidthContentionWeight: 1, Ledger: l}
+	pod := goldenPod(map[string]string{"scv/memory": "4000"})
+	pod.Annotations = map[string]string{filter.BandwidthHeavyAnnotation: "true"}
+	expectPreferred(t, scoreTotals(t, opts, nil, pod, nodes, nil), "calm", "saturated")
+	pod.Annotations = nil
+	if totals := scoreTotals(t, opts, nil, pod, nodes, nil); totals["calm"] != totals["saturated"] {
+		t.Errorf("a pod that is not bandwidth-heavy scores the nodes %v, want them equal", totals)
+	}
+}
+
+func TestCpuRatioRanking(t *testing.T) {
+	withCpu := func(n SelfTestNode, cpu string) SelfTestNode {
+		n.Node.Status.Allocatable = v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)}
+		return n
+	}
+	card := goldenCard(12000, 12000, 1500)
+	nodes := []SelfTestNode{withCpu(goldenNode("rich", card, card), "32"), withCpu(goldenNode("starved", card, card), "4")}
+	pod := goldenPod(map[string]string{"scv/memory": "4000"})
+	pod.Annotations = map[string]string{filter.CpuPerGpuAnnotation: "8"}
+	opts := Options{CpuRatioWeight: 1}
+	expectPreferred(t, scoreTotals(t, opts, nil, pod, nodes, nil), "rich", "starved")
+	if got := CalculateCpuRatioScore(opts, &nodes[1].Scv, pod, nodeInfo(t, nodes[1].Node)); got >= 0 {
+		t.Errorf("CalculateCpuRatioScore() for 2 CPUs per card = %v, want a penalty", got)
+	}
+}
+
+func TestThermalDerateRanking(t *testing.T) {
+	curve, err := ParseThermalCurve("70:100,90:60")
+	if err != nil {
+		t.Fatal(err)
+	}
+	card := goldenCard(8000, 12000, 1500)
+	nodes := []SelfTestNode{
+		withAnnotations(goldenNode("cool", card), map[string]string{CardTemperatureAnnotation: "55"}),
+		withAnnotations(goldenNode("hot", card), map[string]string{CardTemperatureAnnotation: "88"}),
+	}
+	pod := goldenPod(map[string]string{"scv/memory": "4000"})
+	pod.Annotations = map[string]string{filter.BandwidthHeavyAnnotation: "true"}
+	expectPreferred(t, scoreTotals(t, Options{ThermalCurve: curve}, nil, pod, nodes, nil), "cool", "hot")
+	// Without a curve, or for pods that are not bandwidth-sensitive, heat is ignored.
+	if totals := scoreTotals(t, Options{}, nil, pod, nodes, nil); totals["cool"] != totals["hot"] {
+		t.Errorf("without a curve the nodes score %v, want them equal", totals)
+	}
+	pod.Annotations = nil
+	if totals := scoreTotals(t, Options{ThermalCurve: curve}, nil, pod, nodes, nil); totals["cool"] != totals["hot"] {
+		t.Errorf("a pod that is not bandwidth-heavy scores the nodes %v, want them equal", totals)
+	}
+}
+
+func TestRecentlyFreedRanking(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := ledger.New()
+	l.Reserve("finished", ledger.Reservation{Node: "freed", Cards: []int{0}, Memory: 16000})
+	l.Free("finished", now.Add(-2*time.Minute))
+	reported := func(n SelfTestNode, at time.Time) SelfTestNode {
+		n.Scv.Status.UpdateTime = &metav1.Time{Time: at}
+		return n
+	}
+	card := goldenCard(20000, 24000, 1500)
+	nodes := []SelfTestNode{reported(goldenNode("freed", card), now.Add(-time.Minute)), reported(goldenNode("idle", card), now.Add(-time.Minute))}
+	opts := Options{RecentlyFreedWeight: 1, Ledger: l, Now: now}
+	large := goldenPod(map[string]string{"scv/memory": "16000"})
+	expectPreferred(t, scoreTotals(t, opts, nil, large, nodes, nil), "freed", "idle")
+	// Until the SCV reports after the release, the freed memory may not be visible.
+	lagging := []SelfTestNode{reported(goldenNode("freed", card), now.Add(-3*time.Minute)), nodes[1]}
+	if totals := scoreTotals(t, opts, nil, large, lagging, nil); totals["freed"] != totals["idle"] {
+		t.Errorf("before the SCV reports the release the nodes score %v, want them equal", totals)
+	}
+	small := goldenPod(map[string]string{"scv/memory": "4000"})
+	if totals := scoreTotals(t, opts, nil, small, nodes, nil); totals["freed"] != totals["idle"] {
+		t.Errorf("a small request scores the nodes %v, want them equal", totals)
+	}
+}
+
+func TestPlacementHistoryRanking(t *testing.T) {
+	fake := clock.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	history := stats.NewPlacementHistory(fake, time.Hour)
+	history.Record("nightly-train", "warm")
+	card := goldenCard(8000, 12000, 1500)
+	nodes := []SelfTestNode{goldenNode("warm", card), goldenNode("new", card)}
+	opts := Options{PlacementHistoryWeight: 1, History: history}
+	pod := goldenPod(map[string]string{"scv/memory": "4000"})
+	pod.Annotations = map[string]string{filter.JobSignatureAnnotation: "nightly-train"}
+	expectPreferred(t, scoreTotals(t, opts, nil, pod, nodes, nil), "warm", "new")
+
+	other := goldenPod(map[string]string{"scv/memory": "4000"})
+	other.Annotations = map[string]string{filter.JobSignatureAnnotation: "ad-hoc"}
+	if totals := scoreTotals(t, opts, nil, other, nodes, nil); totals["warm"] != totals["new"] {
+		t.Errorf("a job without history scores the nodes %v, want them equal", totals)
+	}
+	fake.Step(2 * time.Hour)
+	if totals := scoreTotals(t, opts, nil, pod, nodes, nil); totals["warm"] != totals["new"] {
+		t.Errorf("after the history expired the nodes score %v, want them equal", totals)
+	}
+}
+
+func TestPcieSwitchRanking(t *testing.T) {
+	card := goldenCard(8000, 12000, 1500)
+	nodes := []SelfTestNode{
+		withAnnotations(goldenNode("same-switch", card, card, card, card), map[string]string{CardPcieSwitchAnnotation: "sw0,sw0,sw1,sw2"}),
+		withAnnotations(goldenNode("cross-switch", card, card, card, card), map[string]string{CardPcieSwitchAnnotation: "sw0,sw1,sw2,sw3"}),
+	}
+	opts := Options{PcieSwitchWeight: 1}
+	pod := goldenPod(map[string]string{"scv/number": "2"})
+	expectPreferred(t, scoreTotals(t, opts, nil, pod, nodes, nil), "same-switch", "cross-switch")
+	single := goldenPod(map[string]string{"scv/number": "1"})
+	if totals := scoreTotals(t, opts, nil, single, nodes, nil); totals["same-switch"] != totals["cross-switch"] {
+		t.Errorf("a single-card pod scores the nodes %v, want them equal", totals)
+	}
+}
+
+func TestModeMatchRanking(t *testing.T) {
+	card := goldenCard(8000, 12000, 1500)
+	nodes := []SelfTestNode{
+		withAnnotations(goldenNode("mig", card), map[string]string{CardModeAnnotation: "mig"}),
+		withAnnotations(goldenNode("default", card), map[string]string{CardModeAnnotation: "default"}),
+	}
+	opts := Options{ModeMatchWeight: 1}
+	pod := goldenPod(map[string]string{"scv/memory": "4000"})
+	pod.Annotations = map[string]string{ModeAnnotation: "mig"}
+	expectPreferred(t, scoreTotals(t, opts, nil, pod, nodes, nil), "mig", "default")
+	if got := CalculateModeMatchScore(opts, &nodes[1].Scv, pod, nodes[1].Node); got >= 0 {
+		t.Errorf("CalculateModeMatchScore() for a card needing a mode switch = %v, want a penalty", got)
+	}
+}
+
+func TestSidecarWarmRanking(t *testing.T) {
+	card := goldenCard(8000, 12000, 1500)
+	cached := goldenNode("cached", card)
+	cached.Node.Status.Images = []v1.ContainerImage{{Names: []string{"istio/proxyv2:1.5.0"}}}
+	nodes := []SelfTestNode{
+		withAnnotations(goldenNode("warm", card), map[string]string{WarmSidecarsAnnotation: "istio/proxyv2:1.5.0"}),
+		cached,
+		goldenNode("cold", card),
+		goldenNode("running", card),
+	}
+	sidecar := goldenPod(nil)
+	sidecar.Spec.Containers = []v1.Container{{Name: "istio-proxy", Image: "istio/proxyv2:1.5.0"}}
+	pods := map[string][]*v1.Pod{"running": {sidecar}}
+	opts := Options{SidecarWarmWeight: 1}
+	pod := goldenPod(map[string]string{"scv/memory": "4000"})
+	pod.Annotations = map[string]string{SidecarImageAnnotation: "istio/proxyv2:1.5.0"}
+	totals := scoreTotals(t, opts, nil, pod, nodes, pods)
+	for _, warm := range []string{"warm", "cached", "running"} {
+		expectPreferred(t, totals, warm, "cold")
+	}
+	if totals := scoreTotals(t, Options{}, nil, pod, nodes, pods); totals["warm"] != totals["cold"] {
+		t.Errorf("by default the nodes score %v, want them equal", totals)
+	}
+}
+
+func TestVendorBalanceRanking(t *testing.T) {
+	card := goldenCard(8000, 12000, 1500)
+	amd := goldenNode("amd", card)
+	amd.Node.Labels = map[string]string{filter.VendorLabel: "amd"}
+	nodes := []SelfTestNode{goldenNode("nvidia", card), amd, goldenNode("nvidia-full", goldenCard(0, 12000, 1500))}
+	list := scv.ScvList{}
+	vendors := map[string]string{}
+	for _, n := range nodes {
+		list.Items = append(list.Items, n.Scv)
+		vendors[n.Node.Name] = filter.NodeVendor(n.Node)
+	}
+	state := framework.NewCycleState()
+	collection.CollectVendorUsage(state, list, vendors, ledger.New())
+	opts := Options{VendorBalanceWeight: 1}
+	totals := scoreTotals(t, opts, state, goldenPod(map[string]string{"scv/memory": "4000"}), nodes, nil)
+	expectPreferred(t, totals, "amd", "nvidia")
+	pinned := goldenPod(map[string]string{"scv/memory": "4000"})
+	pinned.Annotations = map[string]string{filter.VendorLabel: "nvidia"}
+	if totals := scoreTotals(t, opts, state, pinned, nodes, nil); totals["amd"] != totals["nvidia"] {
+		t.Errorf("a pod requiring a vendor scores %v, want the fleets equal", totals)
+	}
+}
+
+func TestClockUniformityRanking(t *testing.T) {
+	nodes := []SelfTestNode{
+		goldenNode("uniform", goldenCard(8000, 12000, 1500), goldenCard(8000, 12000, 1500)),
+		goldenNode("mismatched", goldenCard(8000, 12000, 1200), goldenCard(8000, 12000, 1800)),
+	}
+	pod := goldenPod(map[string]string{"scv/number": "2", "scv/memory": "4000"})
+	base := scoreTotals(t, Options{}, nil, pod, nodes, nil)
+	totals := scoreTotals(t, Options{ClockUniformityWeight: 5}, nil, pod, nodes, nil)
+	expectPreferred(t, totals, "uniform", "mismatched")
+	if gain := totals["uniform"] - totals["mismatched"]; gain <= base["uniform"]-base["mismatched"] {
+		t.Errorf("clock uniformity widens the lead of the uniform node to %v, want more than %v", gain, base["uniform"]-base["mismatched"])
+	}
+	single := goldenPod(map[string]string{"scv/memory": "4000"})
+	base = scoreTotals(t, Options{}, nil, single, nodes, nil)
+	totals = scoreTotals(t, Options{ClockUniformityWeight: 5}, nil, single, nodes, nil)
+	for name := range totals {
+		if totals[name] != base[name] {
+			t.Errorf("a single-card pod scores %v on node %v with the weight set, want %v", totals[name], name, base[name])
+		}
+	}
+}
+
+func TestKernelErrorRanking(t *testing.T) {
+	card := goldenCard(8000, 12000, 1500)
+	nodes := []SelfTestNode{
+		withAnnotations(goldenNode("elevated", card), map[string]string{XidErrorRateAnnotation: "12"}),
+		withAnnotations(goldenNode("clean", card), map[string]string{XidErrorRateAnnotation: "0"}),
+		goldenNode("unreported", card),
+	}
+	pod := goldenPod(map[string]string{"scv/memory": "4000"})
+	totals := scoreTotals(t, Options{KernelErrorWeight: 2}, nil, pod, nodes, nil)
+	expectPreferred(t, totals, "clean", "elevated")
+	if totals["unreported"] != totals["clean"] {
+		t.Errorf("node without a reported rate scores %v, want it neutral at %v", totals["unreported"], totals["clean"])
+	}
+	if totals := scoreTotals(t, Options{}, nil, pod, nodes, nil); totals["clean"] != totals["elevated"] {
+		t.Errorf("by default the nodes score %v, want them equal", totals)
+	}
+}
+
+func TestMigRepartitionRanking(t *testing.T) {
+	card := goldenCard(8000, 12000, 1500)
+	nodes := []SelfTestNode{
+		withAnnotations(goldenNode("partitioned", card), map[string]string{CardMigProfilesAnnotation: "3g.20gb+1g.5gb"}),
+		withAnnotations(goldenNode("repartition", card), map[string]string{CardMigProfilesAnnotation: "1g.5gb+1g.5gb"}),
+	}
+	opts := Options{MigRepartitionWeight: 1}
+	pod := goldenPod(map[string]string{"scv/memory": "4000"})
+	pod.Annotations = map[string]string{MigProfileAnnotation: "3g.20gb"}
+	expectPreferred(t, scoreTotals(t, opts, nil, pod, nodes, nil), "partitioned", "repartition")
+	if got := CalculateMigRepartitionScore(opts, &nodes[1].Scv, pod, nodes[1].Node); got >= 0 {
+		t.Errorf("CalculateMigRepartitionScore() for a card needing repartition = %v, want a penalty", got)
+	}
+	if totals := scoreTotals(t, Options{}, nil, pod, nodes, nil); totals["partitioned"] != totals["repartition"] {
+		t.Errorf("by default the nodes score %v, want them equal", totals)
+	}
+}
+
+func TestInteractiveIsolationRanking(t *testing.T) {
+	l := ledger.New()
+	l.Reserve("notebook", ledger.Reservation{Node: "interactive", Cards: []int{0}, Class: filter.ClassInteractive})
+	l.Reserve("train-1", ledger.Reservation{Node: "batch", Cards: []int{0}, Class: filter.ClassBatch})
+	l.Reserve("train-2", ledger.Reservation{Node: "batch", Cards: []int{0}, Class: filter.ClassBatch})
+	card := goldenCard(8000, 12000, 1500)
+	nodes := []SelfTestNode{goldenNode("free", card), goldenNode("interactive", card), goldenNode("batch", card)}
+	opts := Options{InteractiveIsolationWeight: 1, Ledger: l}
+	pod := goldenPod(map[string]string{"scv/memory": "4000", filter.WorkloadClassLabel: filter.ClassInteractive})
+	totals := scoreTotals(t, opts, nil, pod, nodes, nil)
+	expectPreferred(t, totals, "free", "batch")
+	expectPreferred(t, totals, "interactive", "batch")
+	if totals["free"] != totals["interactive"] {
+		t.Errorf("free and interactive-only nodes score %v and %v, want them equal", totals["free"], totals["interactive"])
+	}
+	batch := goldenPod(map[string]string{"scv/memory": "4000", filter.WorkloadClassLabel: filter.ClassBatch})
+	if totals := scoreTotals(t, opts, nil, batch, nodes, nil); totals["free"] != totals["batch"] {
+		t.Errorf("a batch pod scores %v, want the nodes equal", totals)
+	}
+}
+
+func TestFirstConsumerRanking(t *testing.T) {
+	l := ledger.New()
+	l.Reserve("tenant", ledger.Reservation{Node: "active", Cards: []int{0}})
+	card := goldenCard(12000, 12000, 1500)
+	nodes := []SelfTestNode{goldenNode("active", card), goldenNode("idle", card)}
+	pod := goldenPod(map[string]string{"scv/memory": "4000"})
+	expectPreferred(t, scoreTotals(t, Options{FirstConsumerPenalty: 10, Ledger: l}, nil, pod, nodes, nil), "active", "idle")
+	if totals := scoreTotals(t, Options{Ledger: l}, nil, pod, nodes, nil); totals["active"] != totals["idle"] {
+		t.Errorf("without a penalty the nodes score %v, want them equal", totals)
+	}
+
+	// A faster idle node wins until the penalty outweighs its lead.
+	nodes[1] = goldenNode("idle", goldenCard(12000, 12000, 1800))
+	base := scoreTotals(t, Options{Ledger: l}, nil, pod, nodes, nil)
+	lead := base["idle"] - base["active"]
+	if lead <= 1 {
+		t.Fatalf("idle node leads by %v, want a lead to overcome", lead)
+	}
+	expectPreferred(t, scoreTotals(t, Options{FirstConsumerPenalty: uint64(lead / 2), Ledger: l}, nil, pod, nodes, nil), "idle", "active")
+	expectPreferred(t, scoreTotals(t, Options{FirstConsumerPenalty: uint64(lead) + 1, Ledger: l}, nil, pod, nodes, nil), "active", "idle")
+}
+
+func TestClockLockRanking(t *testing.T) {
+	card := goldenCard(8000, 12000, 1500)
+	supported := map[string]string{filter.ClockLockingAnnotation: filter.ClockLockingSupported}
+	locked := func(clock string) map[string]string {
+		return map[string]string{filter.ClockLockingAnnotation: filter.ClockLockingSupported, filter.CardLockedClockAnnotation: clock}
+	}
+	nodes := []SelfTestNode{
+		withAnnotations(goldenNode("locked", card), locked("1500")),
+		withAnnotations(goldenNode("other-clock", card), locked("1200")),
+		withAnnotations(goldenNode("unlocked", card), supported),
+	}
+	opts := Options{ClockLockWeight: 1}
+	pod := goldenPod(map[string]string{"scv/memory": "4000", "scv/clock": "1500"})
+	pod.Annotations = map[string]string{filter.LockClocksAnnotation: "true"}
+	totals := scoreTotals(t, opts, nil, pod, nodes, nil)
+	expectPreferred(t, totals, "locked", "unlocked")
+	expectPreferred(t, totals, "locked", "other-clock")
+	if totals := scoreTotals(t, opts, nil, goldenPod(map[string]string{"scv/memory": "4000", "scv/clock": "1500"}), nodes, nil); totals["locked"] != totals["unlocked"] {
+		t.Errorf("a pod not locking clocks scores %v, want the nodes equal", totals)
+	}
+}
+
+func TestExternalScoreRanking(t *testing.T) {
+	pod := goldenPod(map[string]string{"scv/memory": "4000"})
+	nodes := []SelfTestNode{
+		withAnnotations(goldenNode("favoured", goldenCard(6000, 12000, 1500)), map[string]string{ExternalScoreAnnotation: "80"}),
+		withAnnotations(goldenNode("disfavoured", goldenCard(10000, 12000, 1500)), map[string]string{ExternalScoreAnnotation: "20"}),
+	}
+	gap := func(weight uint64) float64 {
+		totals := scoreTotals(t, Options{ExternalScoreWeight: weight}, nil, pod, nodes, nil)
+		return totals["favoured"] - totals["disfavoured"]
+	}
+	base := gap(0)
+	for _, weight := range []uint64{1, 2, 5} {
+		if shift := gap(weight) - base; shift != float64(60*weight) {
+			t.Errorf("weight %v shifts the gap by %v, want %v", weight, shift, 60*weight)
+		}
+	}
+
+	bare := []SelfTestNode{goldenNode("roomy", goldenCard(10000, 12000, 1500)), goldenNode("tight", goldenCard(6000, 12000, 1500))}
+	want := scoreTotals(t, Options{}, nil, pod, bare, nil)
+	for _, policy := range []string{"", MissingDataPenalize, MissingDataNeutral} {
+		totals := scoreTotals(t, Options{ExternalScoreWeight: 2, MissingDataPolicy: policy}, nil, pod, bare, nil)
+		if totals["roomy"]-totals["tight"] != want["roomy"]-want["tight"] {
+			t.Errorf("policy %q: nodes without external scores rank %v, want %v", policy, totals, want)
+		}
+	}
+	if totals := scoreTotals(t, Options{ExternalScoreWeight: 2}, nil, pod, bare, nil); totals["roomy"] != want["roomy"] || totals["tight"] != want["tight"] {
+		t.Errorf("by default nodes without external scores score %v, want %v", totals, want)
+	}
+}
+
+func TestMpsReuseRanking(t *testing.T) {
+	l := ledger.New()
+	l.Reserve("client", ledger.Reservation{Node: "ledger-mps", Cards: []int{0}, Mps: true})
+	card := goldenCard(8000, 12000, 1500)
+	nodes := []SelfTestNode{
+		withAnnotations(goldenNode("reported-mps", card), map[string]string{CardMpsActiveAnnotation: "true"}),
+		withAnnotations(goldenNode("ledger-mps", card), map[string]string{CardMpsActiveAnnotation: "false"}),
+		withAnnotations(goldenNode("no-mps", card), map[string]string{CardMpsActiveAnnotation: "false"}),
+	}
+	opts := Options{MpsReuseWeight: 1, Ledger: l}
+	pod := goldenPod(map[string]string{"scv/memory": "4000"})
+	pod.Annotations = map[string]string{ModeAnnotation: ModeMps}
+	totals := scoreTotals(t, opts, nil, pod, nodes, nil)
+	expectPreferred(t, totals, "reported-mps", "no-mps")
+	expectPreferred(t, totals, "ledger-mps", "no-mps")
+	if totals := scoreTotals(t, opts, nil, goldenPod(map[string]string{"scv/memory": "4000"}), nodes, nil); totals["reported-mps"] != totals["no-mps"] {
+		t.Errorf("a pod not using MPS scores %v, want the nodes equal", totals)
+	}
+}
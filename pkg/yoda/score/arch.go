@@ -0,0 +1,76 @@
+package score
+
+import (
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+const (
+	TargetArchAnnotation = "yoda.gpu/target-arch"
+	CardArchAnnotation   = "yoda.gpu/card-arch"
+
+	ArchExactScore      = 100
+	ArchCompatibleScore = 50
+	ArchJitScore        = -50
+)
+
+// ArchScore compares a card's compute architecture with the one the pod was
+// compiled for. Cubins run unchanged on a newer minor revision of the same major
+// architecture; anything else needs JIT compilation and is penalized. A card
+// with an unknown architecture scores zero.
+func ArchScore(target, arch string) int64 {
+	tMajor, tMinor, ok := parseArch(target)
+	if !ok {
+		return 0
+	}
+	aMajor, aMinor, ok := parseArch(arch)
+	switch {
+	case !ok:
+		return 0
+	case aMajor != tMajor || aMinor < tMinor:
+		return ArchJitScore
+	case aMinor == tMinor:
+		return ArchExactScore
+	default:
+		return ArchCompatibleScore
+	}
+}
+
+func CalculateArchScore(opts Options, s *scv.Scv, pod *v1.Pod, node *v1.Node) float64 {
+	target, ok := pod.GetAnnotations()[TargetArchAnnotation]
+	if !ok || opts.ArchMatchWeight == 0 {
+		return 0
+	}
+	if _, _, fits := filter.SelectCards(pod, s, nil); !fits {
+		return 0
+	}
+	// FittingCardsScore averages unsigned scores, so shift the JIT penalty to zero.
+	match := FittingCardsScore(s, pod, func(i int, card scv.Card) uint64 {
+		arch, _ := filter.CardAnnotation(node, CardArchAnnotation, i)
+		return uint64(ArchScore(target, arch) - ArchJitScore)
+	})
+	return (float64(match) + ArchJitScore) * float64(opts.ArchMatchWeight)
+}
+
+// parseArch splits an architecture like sm_86 into its major and minor revision.
+func parseArch(arch string) (int, int, bool) {
+	digits := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(arch)), "sm_")
+	if len(digits) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(digits[:len(digits)-1])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(digits[len(digits)-1:])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
@@ -0,0 +1,31 @@
+package score
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// AttestationFreshness is the age at which an attestation stops earning a bonus.
+const AttestationFreshness = 24 * time.Hour
+
+// CalculateAttestationScore prefers recently attested nodes for pods that require attestation.
+func CalculateAttestationScore(opts Options, pod *v1.Pod, node *v1.Node) uint64 {
+	if opts.AttestationWeight == 0 || !filter.PodAnnotationIsTrue(pod, filter.RequireAttestationAnnotation) {
+		return 0
+	}
+	at, ok := filter.NodeAttestedAt(node)
+	if !ok {
+		return 0
+	}
+	age := opts.Now.Sub(at)
+	if age < 0 {
+		age = 0
+	}
+	if age >= AttestationFreshness {
+		return 0
+	}
+	return uint64((AttestationFreshness-age)*100/AttestationFreshness) * opts.AttestationWeight
+}
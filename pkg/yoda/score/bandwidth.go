@@ -0,0 +1,27 @@
+package score
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// CalculateBandwidthContentionScore keeps bandwidth-heavy pods off cards that
+// already host other bandwidth-heavy pods, per the ledger.
+func CalculateBandwidthContentionScore(opts Options, s *scv.Scv, pod *v1.Pod, nodeName string) uint64 {
+	if opts.BandwidthContentionWeight == 0 || opts.Ledger == nil || !filter.PodAnnotationIsTrue(pod, filter.BandwidthHeavyAnnotation) {
+		return 0
+	}
+	tenants := opts.Ledger.CardReservations(nodeName)
+	return FittingCardsScore(s, pod, func(i int, card scv.Card) uint64 {
+		var heavy uint64
+		for _, r := range tenants[i] {
+			if r.BandwidthHeavy {
+				heavy++
+			}
+		}
+		return 100 / (1 + heavy)
+	}) * opts.BandwidthContentionWeight
+}
@@ -0,0 +1,36 @@
+package score
+
+import (
+	"math"
+
+	v1 "k8s.io/api/core/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// CalculateBinpack2DScore packs pods onto cards whose memory and compute would
+// both be highly and evenly used after placement, so neither dimension is left stranded.
+func CalculateBinpack2DScore(opts Options, s *scv.Scv, pod *v1.Pod, nodeName string) uint64 {
+	_, number := filter.PodFitsNumber(pod, s)
+	_, memory := filter.PodFitsMemory(number, pod, s)
+	compute := filter.PodCompute(pod)
+	var reserved map[int]uint
+	if opts.Ledger != nil {
+		reserved = opts.Ledger.ReservedCompute(nodeName)
+	}
+	return FittingCardsScore(s, pod, func(i int, card scv.Card) uint64 {
+		if card.TotalMemory == 0 || card.FreeMemory > card.TotalMemory {
+			return 0
+		}
+		request := float64(compute) / 100
+		if compute == 0 {
+			// Without a declared share, assume compute scales with memory
+			request = float64(memory) / float64(card.TotalMemory)
+		}
+		mem := float64(card.TotalMemory-card.FreeMemory+memory) / float64(card.TotalMemory)
+		comp := math.Min(float64(reserved[i])/100+request, 1)
+		return uint64((1 - math.Abs(mem-comp)) * (mem + comp) / 2 * 100)
+	})
+}
@@ -0,0 +1,87 @@
+package score
+
+import (
+	"math"
+
+	"k8s.io/klog"
+)
+
+// Term is one named contribution to a node's raw score.
+type Term struct {
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+type Breakdown []Term
+
+func (b Breakdown) Total() float64 {
+	var total float64
+	for _, t := range b {
+		total += t.Score
+	}
+	return total
+}
+
+// coreTerms measure how well the node's GPUs fit the pod; every other
+// positive term is a bonus.
+var coreTerms = map[string]bool{
+	"basic":      true,
+	"allocate":   true,
+	"actual":     true,
+	"binpack-2d": true,
+}
+
+// CapBonuses scales the positive bonus terms down proportionally so together
+// they contribute at most limit, keeping core fit dominant. Zero disables the cap.
+func CapBonuses(b Breakdown, limit float64) Breakdown {
+	if limit <= 0 {
+		return b
+	}
+	var bonus float64
+	for _, t := range b {
+		if isBonus(t) {
+			bonus += t.Score
+		}
+	}
+	if bonus <= limit {
+		return b
+	}
+	for i, t := range b {
+		if isBonus(t) {
+			b[i].Score = t.Score * limit / bonus
+		}
+	}
+	return b
+}
+
+// isBonus reports whether the term is a finite positive bonus. Broken terms
+// are left for Sanitize.
+func isBonus(t Term) bool {
+	return !coreTerms[t.Name] && t.Score > 0 && !math.IsInf(t.Score, 0)
+}
+
+// Sanitize replaces NaN and infinite terms with the neutral value so a broken
+// term cannot corrupt the node's ranking.
+func Sanitize(b Breakdown, neutral float64, nodeName string) Breakdown {
+	for i, t := range b {
+		if math.IsNaN(t.Score) || math.IsInf(t.Score, 0) {
+			klog.Warningf("node %v: score term %v is %v, using neutral value %v", nodeName, t.Name, t.Score, neutral)
+			b[i].Score = neutral
+		}
+	}
+	return b
+}
+
+// ToNodeScore converts a raw score to the framework's integer score, clamping
+// values that do not fit.
+func ToNodeScore(score float64) int64 {
+	switch {
+	case math.IsNaN(score):
+		return 0
+	case score >= math.MaxInt64:
+		return math.MaxInt64
+	case score <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(score)
+}
@@ -0,0 +1,129 @@
+package score
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCapBonuses(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit float64
+		in    Breakdown
+		want  Breakdown
+	}{
+		{
+			name:  "disabled",
+			limit: 0,
+			in:    Breakdown{{Name: "basic", Score: 100}, {Name: "arch", Score: 300}},
+			want:  Breakdown{{Name: "basic", Score: 100}, {Name: "arch", Score: 300}},
+		},
+		{
+			name:  "bonuses under the limit are kept",
+			limit: 100,
+			in:    Breakdown{{Name: "basic", Score: 100}, {Name: "arch", Score: 60}, {Name: "external", Score: 40}},
+			want:  Breakdown{{Name: "basic", Score: 100}, {Name: "arch", Score: 60}, {Name: "external", Score: 40}},
+		},
+		{
+			name:  "bonuses scaled proportionally",
+			limit: 100,
+			in:    Breakdown{{Name: "basic", Score: 100}, {Name: "arch", Score: 300}, {Name: "external", Score: 100}},
+			want:  Breakdown{{Name: "basic", Score: 100}, {Name: "arch", Score: 75}, {Name: "external", Score: 25}},
+		},
+		{
+			name:  "core terms and penalties untouched",
+			limit: 50,
+			in:    Breakdown{{Name: "allocate", Score: 500}, {Name: "binpack-2d", Score: 500}, {Name: "arch", Score: 100}, {Name: "drain", Score: -200}},
+			want:  Breakdown{{Name: "allocate", Score: 500}, {Name: "binpack-2d", Score: 500}, {Name: "arch", Score: 50}, {Name: "drain", Score: -200}},
+		},
+		{
+			name:  "infinite bonuses left for Sanitize",
+			limit: 50,
+			in:    Breakdown{{Name: "arch", Score: 100}, {Name: "external", Score: math.Inf(1)}},
+			want:  Breakdown{{Name: "arch", Score: 50}, {Name: "external", Score: math.Inf(1)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CapBonuses(append(Breakdown(nil), tt.in...), tt.limit)
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("term %v = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCapBonusesKeepsCoreRanking(t *testing.T) {
+	// The better fit loses on raw totals to a node with large bonuses, but
+	// wins once the bonuses are capped below the core gap.
+	fit := Breakdown{{Name: "basic", Score: 300}, {Name: "arch", Score: 50}}
+	bonused := Breakdown{{Name: "basic", Score: 200}, {Name: "arch", Score: 200}, {Name: "external", Score: 200}}
+	if fit.Total() >= bonused.Total() {
+		t.Fatalf("uncapped totals %v, %v: want the bonused node ahead", fit.Total(), bonused.Total())
+	}
+	fitTotal := CapBonuses(fit, 80).Total()
+	bonusedTotal := CapBonuses(bonused, 80).Total()
+	if fitTotal <= bonusedTotal {
+		t.Errorf("capped totals %v, %v: want the better fit ahead", fitTotal, bonusedTotal)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name    string
+		neutral float64
+		in      Breakdown
+		want    Breakdown
+	}{
+		{
+			name:    "finite terms are kept",
+			neutral: 0,
+			in:      Breakdown{{Name: "basic", Score: 100}, {Name: "drain", Score: -200}},
+			want:    Breakdown{{Name: "basic", Score: 100}, {Name: "drain", Score: -200}},
+		},
+		{
+			name:    "NaN becomes the neutral value",
+			neutral: 10,
+			in:      Breakdown{{Name: "basic", Score: 100}, {Name: "cpu-ratio", Score: math.NaN()}},
+			want:    Breakdown{{Name: "basic", Score: 100}, {Name: "cpu-ratio", Score: 10}},
+		},
+		{
+			name:    "infinities become the neutral value",
+			neutral: 0,
+			in:      Breakdown{{Name: "external", Score: math.Inf(1)}, {Name: "sysmem", Score: math.Inf(-1)}},
+			want:    Breakdown{{Name: "external", Score: 0}, {Name: "sysmem", Score: 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sanitize(append(Breakdown(nil), tt.in...), tt.neutral, "node")
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("term %v = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+			if total := got.Total(); math.IsNaN(total) || math.IsInf(total, 0) {
+				t.Errorf("Total() = %v, want a finite score", total)
+			}
+		})
+	}
+}
+
+func TestToNodeScore(t *testing.T) {
+	tests := []struct {
+		score float64
+		want  int64
+	}{
+		{score: 120.7, want: 120},
+		{score: math.NaN(), want: 0},
+		{score: math.Inf(1), want: math.MaxInt64},
+		{score: math.Inf(-1), want: math.MinInt64},
+	}
+	for _, tt := range tests {
+		if got := ToNodeScore(tt.score); got != tt.want {
+			t.Errorf("ToNodeScore(%v) = %v, want %v", tt.score, got, tt.want)
+		}
+	}
+}
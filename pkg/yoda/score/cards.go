@@ -0,0 +1,39 @@
+package score
+
+import (
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// FittingCardsScore averages cardScore over the best cards that could serve the
+// pod, taking as many cards as the pod requests.
+func FittingCardsScore(scv *scv.Scv, pod *v1.Pod, cardScore func(i int, card scv.Card) uint64) uint64 {
+	ok, number := filter.PodFitsNumber(pod, scv)
+	if !ok || number == 0 {
+		return 0
+	}
+	_, memory := filter.PodFitsMemory(number, pod, scv)
+	_, clock := filter.PodFitsClock(number, pod, scv)
+	var scores []uint64
+	for i, card := range scv.Status.CardList {
+		if filter.CardFits(memory, clock, card) {
+			scores = append(scores, cardScore(i, card))
+		}
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		return scores[i] > scores[j]
+	})
+	if uint(len(scores)) > number {
+		scores = scores[:number]
+	}
+	var sum uint64
+	for _, s := range scores {
+		sum += s
+	}
+	return sum / uint64(number)
+}
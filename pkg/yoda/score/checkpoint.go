@@ -0,0 +1,37 @@
+package score
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+const CheckpointProgressAnnotation = "yoda.gpu/checkpoint-progress"
+
+// CalculateCheckpointScore prefers cards whose occupants are close to a
+// checkpoint and so cheap to preempt. Empty cards disrupt nobody and score best,
+// occupants without progress are treated as freshly started.
+func CalculateCheckpointScore(opts Options, s *scv.Scv, pod *v1.Pod, info *nodeinfo.NodeInfo) uint64 {
+	if opts.CheckpointAffinityWeight == 0 {
+		return 0
+	}
+	pods := info.Pods()
+	return FittingCardsScore(s, pod, func(i int, card scv.Card) uint64 {
+		occupants := filter.CardOccupants(pods, i)
+		if len(occupants) == 0 {
+			return 100
+		}
+		var progress uint64
+		for _, o := range occupants {
+			p := filter.StrToUint64(o.GetAnnotations()[CheckpointProgressAnnotation])
+			if p > 100 {
+				p = 100
+			}
+			progress += p
+		}
+		return progress / uint64(len(occupants))
+	}) * opts.CheckpointAffinityWeight
+}
@@ -0,0 +1,50 @@
+package score
+
+import (
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// CalculateClockUniformityScore rewards nodes that can give a multi-card
+// request cards running at the same clock, since the slowest card bounds the job.
+// The score drops with the clock spread of the most uniform set of fitting cards.
+// Pods ignoring the clock metric get no score.
+func CalculateClockUniformityScore(opts Options, s *scv.Scv, pod *v1.Pod) uint64 {
+	if opts.ClockUniformityWeight == 0 || opts.IgnoredMetrics[MetricClock] {
+		return 0
+	}
+	ok, number := filter.PodFitsNumber(pod, s)
+	if !ok || number < 2 {
+		return 0
+	}
+	_, memory := filter.PodFitsMemory(number, pod, s)
+	_, clock := filter.PodFitsClock(number, pod, s)
+	var clocks []uint
+	for _, card := range s.Status.CardList {
+		if filter.CardFits(memory, clock, card) {
+			clocks = append(clocks, card.Clock)
+		}
+	}
+	if uint(len(clocks)) < number {
+		return 0
+	}
+	sort.Slice(clocks, func(i, j int) bool {
+		return clocks[i] < clocks[j]
+	})
+	best := uint64(0)
+	for i := 0; i+int(number) <= len(clocks); i++ {
+		low, high := clocks[i], clocks[i+int(number)-1]
+		if high == 0 {
+			continue
+		}
+		if score := uint64(100 - (high-low)*100/high); score > best {
+			best = score
+		}
+	}
+	return best * opts.ClockUniformityWeight
+}
@@ -0,0 +1,26 @@
+package score
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// CalculateClockLockScore prefers cards already locked to the pod's requested
+// clock, or locked at all when the pod did not request one, for pods that
+// need locked clocks.
+func CalculateClockLockScore(opts Options, s *scv.Scv, pod *v1.Pod, node *v1.Node) uint64 {
+	if opts.ClockLockWeight == 0 || opts.IgnoredMetrics[MetricClock] || !filter.PodAnnotationIsTrue(pod, filter.LockClocksAnnotation) {
+		return 0
+	}
+	want := uint(filter.StrToUint64(filter.PodRequirement(pod).Clock))
+	return FittingCardsScore(s, pod, func(i int, card scv.Card) uint64 {
+		locked := filter.CardLockedClock(node, i)
+		if locked > 0 && (want == 0 || locked == want) {
+			return 100
+		}
+		return 0
+	}) * opts.ClockLockWeight
+}
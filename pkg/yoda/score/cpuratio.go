@@ -0,0 +1,30 @@
+package score
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// CalculateCpuRatioScore rewards nodes with enough free CPU per idle card for
+// the pod and penalizes those below in proportion to the shortfall.
+func CalculateCpuRatioScore(opts Options, s *scv.Scv, pod *v1.Pod, info *nodeinfo.NodeInfo) float64 {
+	if opts.CpuRatioWeight == 0 {
+		return 0
+	}
+	want, ok := filter.PodCpuPerGpu(pod)
+	if !ok {
+		return 0
+	}
+	have, ok := filter.NodeCpuPerFreeGpu(info, s)
+	if !ok {
+		return 0
+	}
+	if have >= want {
+		return 100 * float64(opts.CpuRatioWeight)
+	}
+	return -100 * (want - have) / want * float64(opts.CpuRatioWeight)
+}
@@ -0,0 +1,70 @@
+package score
+
+import (
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+)
+
+const DeadlineAnnotation = "yoda.gpu/deadline"
+
+// Urgency grows from 0 to 1 as the pod's RFC3339 deadline comes within the horizon.
+func Urgency(pod *v1.Pod, now time.Time, horizon time.Duration) float64 {
+	value, ok := pod.GetAnnotations()[DeadlineAnnotation]
+	if !ok || horizon <= 0 {
+		return 0
+	}
+	deadline, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		klog.V(3).Infof("invalid deadline %q on pod %v: %v", value, pod.Name, err)
+		return 0
+	}
+	remaining := deadline.Sub(now)
+	if remaining <= 0 {
+		return 1
+	}
+	if remaining >= horizon {
+		return 0
+	}
+	return 1 - float64(remaining)/float64(horizon)
+}
+
+// CalculateStartupScore is the percentage of the pod's images already present on the node.
+func CalculateStartupScore(pod *v1.Pod, node *v1.Node) uint64 {
+	if node == nil || len(pod.Spec.Containers) == 0 {
+		return 0
+	}
+	images := map[string]bool{}
+	for _, image := range node.Status.Images {
+		for _, name := range image.Names {
+			images[normalizeImageName(name)] = true
+		}
+	}
+	var present uint64
+	for _, c := range pod.Spec.Containers {
+		if images[normalizeImageName(c.Image)] {
+			present++
+		}
+	}
+	return present * 100 / uint64(len(pod.Spec.Containers))
+}
+
+func CalculateUrgencyScore(opts Options, pod *v1.Pod, node *v1.Node) uint64 {
+	if opts.DeadlineUrgencyWeight == 0 {
+		return 0
+	}
+	u := Urgency(pod, opts.Now, opts.DeadlineHorizon)
+	return uint64(float64(CalculateStartupScore(pod, node)*opts.DeadlineUrgencyWeight) * u)
+}
+
+func normalizeImageName(name string) string {
+	if strings.Contains(name, "@") {
+		return name
+	}
+	if i := strings.LastIndex(name, ":"); i <= strings.LastIndex(name, "/") {
+		name += ":latest"
+	}
+	return name
+}
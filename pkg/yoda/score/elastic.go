@@ -0,0 +1,27 @@
+package score
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// CalculateElasticScore prefers nodes that can grant an elastic pod more of the
+// cards it could use, relative to its maximum.
+func CalculateElasticScore(opts Options, s *scv.Scv, pod *v1.Pod) uint64 {
+	_, max, ok := filter.PodElasticRange(pod)
+	if !ok {
+		return 0
+	}
+	var reserved map[int]uint64
+	if opts.Ledger != nil {
+		reserved = opts.Ledger.ReservedCardMemory(s.GetName(), pod.GetUID())
+	}
+	cards, _, ok := filter.SelectCards(pod, s, reserved)
+	if !ok {
+		return 0
+	}
+	return uint64(len(cards)) * 100 / uint64(max) * ElasticWeight
+}
@@ -0,0 +1,36 @@
+package score
+
+import (
+	"math"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+)
+
+const (
+	// ExternalScoreAnnotation is a 0-100 desirability score set on the node by an outside optimizer.
+	ExternalScoreAnnotation = "yoda.gpu/external-score"
+
+	MaxExternalScore = 100
+)
+
+// CalculateExternalScore blends in the node's externally supplied score,
+// clamped to 0-100. Nodes with an invalid score are left unchanged, nodes
+// without one are rescored by the missing data policy.
+func CalculateExternalScore(opts Options, node *v1.Node) float64 {
+	if opts.ExternalScoreWeight == 0 {
+		return 0
+	}
+	value, ok := node.GetAnnotations()[ExternalScoreAnnotation]
+	if !ok {
+		return 0
+	}
+	external, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(external) {
+		klog.V(4).Infof("node %v: ignoring invalid external score %q", node.GetName(), value)
+		return 0
+	}
+	external = math.Max(0, math.Min(MaxExternalScore, external))
+	return external * float64(opts.ExternalScoreWeight)
+}
@@ -0,0 +1,50 @@
+package score
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// RecentlyFreedWindow is how long freed capacity keeps attracting large requests.
+const RecentlyFreedWindow = 10 * time.Minute
+
+// CalculateRecentlyFreedScore prefers cards a finished pod just gave back for
+// requests needing at least half a card. A release only counts once the SCV has
+// reported after it, so the freed memory is visible in its free memory.
+func CalculateRecentlyFreedScore(opts Options, s *scv.Scv, pod *v1.Pod) uint64 {
+	if opts.RecentlyFreedWeight == 0 || opts.Ledger == nil || s.Status.UpdateTime == nil {
+		return 0
+	}
+	memory, ok := filter.PodMemory(pod, s)
+	if !ok || memory == 0 {
+		return 0
+	}
+	var best uint64
+	for _, f := range opts.Ledger.RecentlyFreed(s.GetName(), opts.Now.Add(-RecentlyFreedWindow)) {
+		if f.Memory < memory || s.Status.UpdateTime.Time.Before(f.At) {
+			continue
+		}
+		age := opts.Now.Sub(f.At)
+		if age < 0 {
+			age = 0
+		}
+		for _, i := range f.Cards {
+			if i >= len(s.Status.CardList) {
+				continue
+			}
+			card := s.Status.CardList[i]
+			if memory*2 < card.TotalMemory || !filter.CardFitsMemory(memory, card) {
+				continue
+			}
+			if score := uint64((RecentlyFreedWindow - age) * 100 / RecentlyFreedWindow); score > best {
+				best = score
+			}
+		}
+	}
+	return best * opts.RecentlyFreedWeight
+}
@@ -0,0 +1,62 @@
+package score
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+)
+
+func goldenPod(labels map[string]string) *v1.Pod {
+	return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "golden", Namespace: "default", Labels: labels}}
+}
+
+func goldenNode(name string, cards ...scv.Card) SelfTestNode {
+	s := scv.Scv{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	for _, card := range cards {
+		s.Status.CardList = append(s.Status.CardList, card)
+		s.Status.TotalMemorySum += card.TotalMemory
+		s.Status.FreeMemorySum += card.FreeMemory
+		s.Status.CardNumber++
+	}
+	return SelfTestNode{Node: &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}, Scv: s}
+}
+
+func goldenCard(free, total uint64, clock uint) scv.Card {
+	return scv.Card{Health: "Healthy", Model: "golden", Power: 250, TotalMemory: total, FreeMemory: free, Clock: clock, Core: 3000, Bandwidth: 400}
+}
+
+// GoldenCases document the intended ranking of the default scoring.
+var GoldenCases = []SelfTestCase{
+	{
+		Name: "memory request prefers the node with more free memory",
+		Pod:  goldenPod(map[string]string{"scv/memory": "4000"}),
+		Nodes: []SelfTestNode{
+			goldenNode("free", goldenCard(10000, 12000, 1500)),
+			goldenNode("busy", goldenCard(5000, 12000, 1500)),
+		},
+		Expected:  map[string]int64{"free": 100, "busy": 0},
+		Tolerance: 1,
+	},
+	{
+		Name: "only the fitting node earns the card score",
+		Pod:  goldenPod(map[string]string{"scv/memory": "8000"}),
+		Nodes: []SelfTestNode{
+			goldenNode("fits", goldenCard(9000, 12000, 1500)),
+			goldenNode("partial", goldenCard(6000, 12000, 1500)),
+			goldenNode("full", goldenCard(1000, 12000, 1500)),
+		},
+		Expected:  map[string]int64{"fits": 100, "partial": 7, "full": 0},
+		Tolerance: 1,
+	},
+	{
+		Name: "identical nodes score the same",
+		Pod:  goldenPod(map[string]string{"scv/number": "2"}),
+		Nodes: []SelfTestNode{
+			goldenNode("a", goldenCard(8000, 12000, 1500), goldenCard(8000, 12000, 1500)),
+			goldenNode("b", goldenCard(8000, 12000, 1500), goldenCard(8000, 12000, 1500)),
+		},
+		Expected:  map[string]int64{"a": 100, "b": 100},
+		Tolerance: 0,
+	},
+}
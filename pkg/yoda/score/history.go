@@ -0,0 +1,19 @@
+package score
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// CalculatePlacementHistoryScore rewards nodes where the same job recently succeeded.
+func CalculatePlacementHistoryScore(opts Options, pod *v1.Pod, nodeName string) uint64 {
+	if opts.PlacementHistoryWeight == 0 || opts.History == nil {
+		return 0
+	}
+	signature := filter.JobSignature(pod)
+	if signature == "" || !opts.History.Succeeded(signature, nodeName) {
+		return 0
+	}
+	return 100 * opts.PlacementHistoryWeight
+}
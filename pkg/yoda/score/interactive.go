@@ -0,0 +1,27 @@
+package score
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// CalculateInteractiveIsolationScore keeps interactive pods off cards shared
+// with batch pods, per the ledger. Empty and interactive-only cards score full.
+func CalculateInteractiveIsolationScore(opts Options, s *scv.Scv, pod *v1.Pod, nodeName string) uint64 {
+	if opts.InteractiveIsolationWeight == 0 || opts.Ledger == nil || filter.PodWorkloadClass(pod) != filter.ClassInteractive {
+		return 0
+	}
+	tenants := opts.Ledger.CardReservations(nodeName)
+	return FittingCardsScore(s, pod, func(i int, card scv.Card) uint64 {
+		var batch uint64
+		for _, r := range tenants[i] {
+			if r.Class == filter.ClassBatch {
+				batch++
+			}
+		}
+		return 100 / (1 + batch)
+	}) * opts.InteractiveIsolationWeight
+}
@@ -0,0 +1,73 @@
+package score
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+)
+
+const (
+	IgnoreMetricsAnnotation = "yoda.gpu/ignore-metrics"
+	IgnoreMetricsStateKey   = "IgnoreMetrics"
+
+	MetricBandwidth   = "bandwidth"
+	MetricClock       = "clock"
+	MetricCore        = "core"
+	MetricPower       = "power"
+	MetricFreeMemory  = "free-memory"
+	MetricTotalMemory = "total-memory"
+	MetricTemperature = "temperature"
+)
+
+var KnownMetrics = map[string]bool{
+	MetricBandwidth:   true,
+	MetricClock:       true,
+	MetricCore:        true,
+	MetricPower:       true,
+	MetricFreeMemory:  true,
+	MetricTotalMemory: true,
+	MetricTemperature: true,
+}
+
+// IgnoredMetrics are the card metrics left out when scoring a pod.
+type IgnoredMetrics map[string]bool
+
+func (m IgnoredMetrics) Clone() framework.StateData {
+	c := IgnoredMetrics{}
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func ParseIgnoredMetrics(pod *v1.Pod) (IgnoredMetrics, error) {
+	ignored := IgnoredMetrics{}
+	value, ok := pod.GetAnnotations()[IgnoreMetricsAnnotation]
+	if !ok {
+		return ignored, nil
+	}
+	for _, metric := range strings.Split(value, ",") {
+		metric = strings.TrimSpace(metric)
+		if metric == "" {
+			continue
+		}
+		if !KnownMetrics[metric] {
+			return nil, fmt.Errorf("unknown metric %q in %v", metric, IgnoreMetricsAnnotation)
+		}
+		ignored[metric] = true
+	}
+	return ignored, nil
+}
+
+func ReadIgnoredMetrics(state *framework.CycleState) IgnoredMetrics {
+	state.RLock()
+	defer state.RUnlock()
+	d, err := state.Read(IgnoreMetricsStateKey)
+	if err != nil {
+		return nil
+	}
+	ignored, _ := d.(IgnoredMetrics)
+	return ignored
+}
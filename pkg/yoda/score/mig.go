@@ -0,0 +1,59 @@
+package score
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+const (
+	// MigProfileAnnotation is the MIG slice the pod needs, such as 3g.20gb.
+	MigProfileAnnotation = "yoda.gpu/mig-profile"
+	// CardMigProfilesAnnotation lists the free MIG slices of each card, joined
+	// by + within a card and comma-separated across cards.
+	CardMigProfilesAnnotation = "yoda.gpu/card-mig-profiles"
+)
+
+// CardOffersMigProfile reports whether the card at index has a free slice of
+// the profile, and whether the node reports MIG geometry for it at all.
+func CardOffersMigProfile(node *v1.Node, index int, profile string) (bool, bool) {
+	value, ok := filter.CardAnnotation(node, CardMigProfilesAnnotation, index)
+	if !ok {
+		return false, false
+	}
+	for _, p := range strings.Split(value, "+") {
+		if strings.EqualFold(strings.TrimSpace(p), profile) {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// CalculateMigRepartitionScore rewards cards already partitioned to offer the
+// pod's MIG profile and penalizes cards that would have to be drained and
+// repartitioned. Cards without reported geometry count as neutral.
+func CalculateMigRepartitionScore(opts Options, s *scv.Scv, pod *v1.Pod, node *v1.Node) float64 {
+	profile, ok := pod.GetAnnotations()[MigProfileAnnotation]
+	if !ok || profile == "" || opts.MigRepartitionWeight == 0 {
+		return 0
+	}
+	if _, _, fits := filter.SelectCards(pod, s, nil); !fits {
+		return 0
+	}
+	match := FittingCardsScore(s, pod, func(i int, card scv.Card) uint64 {
+		offers, known := CardOffersMigProfile(node, i, profile)
+		switch {
+		case !known:
+			return 100
+		case offers:
+			return 200
+		default:
+			return 0
+		}
+	})
+	return (float64(match) - 100) * float64(opts.MigRepartitionWeight)
+}
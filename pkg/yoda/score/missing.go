@@ -0,0 +1,205 @@
+package score
+
+import (
+	v1 "k8s.io/api/core/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+const (
+	// MissingDataPenalize scores a term at its lowest on nodes without its
+	// data, zero for bonus terms.
+	MissingDataPenalize = "penalize"
+	// MissingDataNeutral gives nodes without the data the middle of the term's range.
+	MissingDataNeutral = "neutral"
+	// MissingDataExcludeTerm drops the term for every node when any feasible node lacks its data.
+	MissingDataExcludeTerm = "exclude-term"
+
+	MissingTermsStateKey = "MissingTerms"
+)
+
+// optionalTerm is a score term computed from node telemetry that may be
+// absent. min is nil for bonus terms, whose lowest score is zero.
+type optionalTerm struct {
+	missing func(opts Options, pod *v1.Pod, node *v1.Node) bool
+	min     func(opts Options) float64
+	max     func(opts Options) float64
+}
+
+func (t optionalTerm) lowest(opts Options) float64 {
+	if t.min == nil {
+		return 0
+	}
+	return t.min(opts)
+}
+
+// optionalTerms covers every term read from node annotations. Terms outside
+// it either fall back to data every node has, like cpu-ratio's allocatable
+// CPU and sidecar-warm's image list, or derate a core term, like the thermal
+// curve, which keeps the full card score when no temperature is reported.
+var optionalTerms = map[string]optionalTerm{
+	"arch": {
+		missing: func(opts Options, pod *v1.Pod, node *v1.Node) bool {
+			_, wanted := pod.GetAnnotations()[TargetArchAnnotation]
+			return opts.ArchMatchWeight > 0 && wanted && !reported(node, CardArchAnnotation)
+		},
+		min: func(opts Options) float64 {
+			return ArchJitScore * float64(opts.ArchMatchWeight)
+		},
+		max: func(opts Options) float64 {
+			return float64(ArchExactScore * opts.ArchMatchWeight)
+		},
+	},
+	"pcie-switch": {
+		missing: func(opts Options, pod *v1.Pod, node *v1.Node) bool {
+			return opts.PcieSwitchWeight > 0 && filter.PodCardCount(pod) >= 2 && !reported(node, CardPcieSwitchAnnotation)
+		},
+		max: func(opts Options) float64 {
+			return float64(100 * opts.PcieSwitchWeight)
+		},
+	},
+	"power-state": {
+		missing: func(opts Options, pod *v1.Pod, node *v1.Node) bool {
+			scored := opts.PowerSaving || filter.PodAnnotationIsTrue(pod, LatencySensitiveAnnotation)
+			return opts.PowerStateWeight > 0 && scored && !reported(node, PowerStateAnnotation)
+		},
+		max: func(opts Options) float64 {
+			return float64(100 * opts.PowerStateWeight)
+		},
+	},
+	"resident-model": {
+		missing: func(opts Options, pod *v1.Pod, node *v1.Node) bool {
+			return opts.ResidentModelWeight > 0 && pod.GetAnnotations()[ModelNameAnnotation] != "" && !reported(node, ResidentModelsAnnotation)
+		},
+		max: func(opts Options) float64 {
+			return float64(100 * opts.ResidentModelWeight)
+		},
+	},
+	"mode-match": {
+		missing: func(opts Options, pod *v1.Pod, node *v1.Node) bool {
+			return opts.ModeMatchWeight > 0 && pod.GetAnnotations()[ModeAnnotation] != "" && !reported(node, CardModeAnnotation)
+		},
+		min: func(opts Options) float64 {
+			return -100 * float64(opts.ModeMatchWeight)
+		},
+		max: func(opts Options) float64 {
+			return 100 * float64(opts.ModeMatchWeight)
+		},
+	},
+	"mps-reuse": {
+		missing: func(opts Options, pod *v1.Pod, node *v1.Node) bool {
+			if opts.MpsReuseWeight == 0 || !PodNeedsMps(pod) || reported(node, CardMpsActiveAnnotation) {
+				return false
+			}
+			if opts.Ledger != nil {
+				for _, tenants := range opts.Ledger.CardReservations(node.GetName()) {
+					for _, r := range tenants {
+						if r.Mps {
+							return false
+						}
+					}
+				}
+			}
+			return true
+		},
+		max: func(opts Options) float64 {
+			return float64(100 * opts.MpsReuseWeight)
+		},
+	},
+	"mig-repartition": {
+		missing: func(opts Options, pod *v1.Pod, node *v1.Node) bool {
+			return opts.MigRepartitionWeight > 0 && pod.GetAnnotations()[MigProfileAnnotation] != "" && !reported(node, CardMigProfilesAnnotation)
+		},
+		min: func(opts Options) float64 {
+			return -100 * float64(opts.MigRepartitionWeight)
+		},
+		max: func(opts Options) float64 {
+			return 100 * float64(opts.MigRepartitionWeight)
+		},
+	},
+	"clock-lock": {
+		missing: func(opts Options, pod *v1.Pod, node *v1.Node) bool {
+			return opts.ClockLockWeight > 0 && !opts.IgnoredMetrics[MetricClock] && filter.PodAnnotationIsTrue(pod, filter.LockClocksAnnotation) && !reported(node, filter.CardLockedClockAnnotation)
+		},
+		max: func(opts Options) float64 {
+			return float64(100 * opts.ClockLockWeight)
+		},
+	},
+	"external": {
+		missing: func(opts Options, pod *v1.Pod, node *v1.Node) bool {
+			return opts.ExternalScoreWeight > 0 && !reported(node, ExternalScoreAnnotation)
+		},
+		max: func(opts Options) float64 {
+			return MaxExternalScore * float64(opts.ExternalScoreWeight)
+		},
+	},
+}
+
+func reported(node *v1.Node, key string) bool {
+	_, ok := node.GetAnnotations()[key]
+	return ok
+}
+
+// MissingTerms are the optional terms excluded from this cycle's scores.
+type MissingTerms map[string]bool
+
+func (m MissingTerms) Clone() framework.StateData {
+	terms := MissingTerms{}
+	for k, v := range m {
+		terms[k] = v
+	}
+	return terms
+}
+
+// CollectMissingTerms records the optional terms some feasible node has no data for.
+func CollectMissingTerms(state *framework.CycleState, opts Options, pod *v1.Pod, nodes []*v1.Node) {
+	terms := MissingTerms{}
+	for name, term := range optionalTerms {
+		for _, node := range nodes {
+			if term.missing(opts, pod, node) {
+				terms[name] = true
+				break
+			}
+		}
+	}
+	state.Lock()
+	state.Write(MissingTermsStateKey, terms)
+	state.Unlock()
+}
+
+func ReadMissingTerms(state *framework.CycleState) MissingTerms {
+	state.RLock()
+	defer state.RUnlock()
+	d, err := state.Read(MissingTermsStateKey)
+	if err != nil {
+		return nil
+	}
+	terms, _ := d.(MissingTerms)
+	return terms
+}
+
+// ApplyMissingDataPolicy rescores the optional terms of the node's breakdown
+// according to the configured policy.
+func ApplyMissingDataPolicy(opts Options, state *framework.CycleState, pod *v1.Pod, node *v1.Node, b Breakdown) Breakdown {
+	var excluded MissingTerms
+	if opts.MissingDataPolicy == MissingDataExcludeTerm {
+		excluded = ReadMissingTerms(state)
+	}
+	for i, t := range b {
+		term, ok := optionalTerms[t.Name]
+		if !ok {
+			continue
+		}
+		switch {
+		case excluded[t.Name]:
+			b[i].Score = 0
+		case !term.missing(opts, pod, node):
+		case opts.MissingDataPolicy == MissingDataNeutral:
+			b[i].Score = (term.lowest(opts) + term.max(opts)) / 2
+		default:
+			b[i].Score = term.lowest(opts)
+		}
+	}
+	return b
+}
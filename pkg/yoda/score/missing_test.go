@@ -0,0 +1,143 @@
+package score
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/collection"
+)
+
+func TestMissingDataPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		term        string
+		opts        Options
+		annotations map[string]string
+		key         string
+		// good and bad are the annotation values of the two reporting nodes;
+		// the third node, "unknown", reports nothing.
+		good, bad string
+		want      map[string]map[string]float64
+	}{
+		{
+			name: "external score",
+			term: "external",
+			opts: Options{ExternalScoreWeight: 1},
+			key:  ExternalScoreAnnotation,
+			good: "80",
+			bad:  "20",
+			want: map[string]map[string]float64{
+				MissingDataPenalize:    {"good": 80, "bad": 20, "unknown": 0},
+				MissingDataNeutral:     {"good": 80, "bad": 20, "unknown": 50},
+				MissingDataExcludeTerm: {"good": 0, "bad": 0, "unknown": 0},
+			},
+		},
+		{
+			name: "kernel errors",
+			term: "kernel-errors",
+			opts: Options{KernelErrorWeight: 1},
+			key:  XidErrorRateAnnotation,
+			good: "0",
+			bad:  "50",
+			// A node without a reported rate has no errors to penalize
+			// and keeps ranking above an elevated one under every policy.
+			want: map[string]map[string]float64{
+				MissingDataPenalize:    {"good": 0, "bad": -50, "unknown": 0},
+				MissingDataNeutral:     {"good": 0, "bad": -50, "unknown": 0},
+				MissingDataExcludeTerm: {"good": 0, "bad": -50, "unknown": 0},
+			},
+		},
+		{
+			name:        "card mode",
+			term:        "mode-match",
+			opts:        Options{ModeMatchWeight: 1},
+			annotations: map[string]string{ModeAnnotation: "mig"},
+			key:         CardModeAnnotation,
+			good:        "mig",
+			bad:         "default",
+			want: map[string]map[string]float64{
+				MissingDataPenalize:    {"good": 100, "bad": -100, "unknown": -100},
+				MissingDataNeutral:     {"good": 100, "bad": -100, "unknown": 0},
+				MissingDataExcludeTerm: {"good": 0, "bad": 0, "unknown": 0},
+			},
+		},
+		{
+			name:        "power state",
+			term:        "power-state",
+			opts:        Options{PowerStateWeight: 1},
+			annotations: map[string]string{LatencySensitiveAnnotation: "true"},
+			key:         PowerStateAnnotation,
+			good:        PowerStateActive,
+			bad:         PowerStateIdle,
+			want: map[string]map[string]float64{
+				MissingDataPenalize:    {"good": 100, "bad": 0, "unknown": 0},
+				MissingDataNeutral:     {"good": 100, "bad": 0, "unknown": 50},
+				MissingDataExcludeTerm: {"good": 0, "bad": 0, "unknown": 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		for policy, want := range tt.want {
+			t.Run(tt.name+"/"+policy, func(t *testing.T) {
+				pod := goldenPod(map[string]string{"scv/memory": "4000"})
+				pod.Annotations = tt.annotations
+				opts := tt.opts
+				opts.MissingDataPolicy = policy
+				nodes := map[string]SelfTestNode{}
+				list := scv.ScvList{}
+				var nodeList []*v1.Node
+				for name, value := range map[string]string{"good": tt.good, "bad": tt.bad, "unknown": ""} {
+					n := goldenNode(name, goldenCard(10000, 12000, 1500))
+					if value != "" {
+						n.Node.Annotations = map[string]string{tt.key: value}
+					}
+					nodes[name] = n
+					list.Items = append(list.Items, n.Scv)
+					nodeList = append(nodeList, n.Node)
+				}
+				state := framework.NewCycleState()
+				if status := collection.CollectMaxValues(state, pod, list); !status.IsSuccess() {
+					t.Fatal(status.Message())
+				}
+				CollectMissingTerms(state, opts, pod, nodeList)
+				totals := map[string]float64{}
+				for name, n := range nodes {
+					info := nodeinfo.NewNodeInfo()
+					if err := info.SetNode(n.Node); err != nil {
+						t.Fatal(err)
+					}
+					b, err := CalculateBreakdown(opts, &n.Scv, state, pod, info)
+					if err != nil {
+						t.Fatal(err)
+					}
+					found := false
+					for _, term := range b {
+						if term.Name == tt.term {
+							found = true
+							if term.Score != want[name] {
+								t.Errorf("node %v: %v = %v, want %v", name, tt.term, term.Score, want[name])
+							}
+						}
+					}
+					if !found {
+						t.Errorf("node %v: no %v term in %v", name, tt.term, b)
+					}
+					totals[name] = b.Total()
+				}
+				// The nodes differ only in the term, so it alone decides the ranking.
+				for a := range want {
+					for b := range want {
+						if want[a] > want[b] && totals[a] <= totals[b] {
+							t.Errorf("node %v total %v does not rank above node %v total %v", a, totals[a], b, totals[b])
+						}
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package score
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+const (
+	// ModeAnnotation is the card mode the pod needs: default, mig or mps.
+	ModeAnnotation     = "yoda.gpu/mode"
+	CardModeAnnotation = "yoda.gpu/card-mode"
+)
+
+// CalculateModeMatchScore rewards cards already in the mode the pod needs and
+// penalizes cards that would have to be drained and switched. Cards with an
+// unknown mode count as neutral.
+func CalculateModeMatchScore(opts Options, s *scv.Scv, pod *v1.Pod, node *v1.Node) float64 {
+	want, ok := pod.GetAnnotations()[ModeAnnotation]
+	if !ok || want == "" || opts.ModeMatchWeight == 0 {
+		return 0
+	}
+	if _, _, fits := filter.SelectCards(pod, s, nil); !fits {
+		return 0
+	}
+	match := FittingCardsScore(s, pod, func(i int, card scv.Card) uint64 {
+		mode, ok := filter.CardAnnotation(node, CardModeAnnotation, i)
+		switch {
+		case !ok:
+			return 100
+		case strings.EqualFold(mode, want):
+			return 200
+		default:
+			return 0
+		}
+	})
+	return (float64(match) - 100) * float64(opts.ModeMatchWeight)
+}
@@ -0,0 +1,34 @@
+package score
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+const (
+	ModelNameAnnotation      = "yoda.gpu/model-name"
+	ResidentModelsAnnotation = "yoda.gpu/resident-models"
+)
+
+// CalculateResidentModelScore rewards cards that already hold the pod's model in
+// memory, with the models of each card in the node annotation separated by ";".
+func CalculateResidentModelScore(opts Options, s *scv.Scv, pod *v1.Pod, node *v1.Node) uint64 {
+	model, ok := pod.GetAnnotations()[ModelNameAnnotation]
+	if !ok || model == "" || opts.ResidentModelWeight == 0 {
+		return 0
+	}
+	return FittingCardsScore(s, pod, func(i int, card scv.Card) uint64 {
+		resident, _ := filter.CardAnnotation(node, ResidentModelsAnnotation, i)
+		for _, m := range strings.Split(resident, ";") {
+			if strings.TrimSpace(m) == model {
+				return 100
+			}
+		}
+		return 0
+	}) * opts.ResidentModelWeight
+}
@@ -0,0 +1,45 @@
+package score
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/ledger"
+)
+
+const (
+	ModeMps = "mps"
+	// CardMpsActiveAnnotation lists, comma-separated, whether each card runs an MPS daemon.
+	CardMpsActiveAnnotation = "yoda.gpu/card-mps-active"
+)
+
+func PodNeedsMps(pod *v1.Pod) bool {
+	return strings.EqualFold(pod.GetAnnotations()[ModeAnnotation], ModeMps)
+}
+
+// CalculateMpsReuseScore prefers, for MPS pods, cards whose MPS daemon is
+// already running, as reported on the node or implied by MPS pods in the ledger.
+func CalculateMpsReuseScore(opts Options, s *scv.Scv, pod *v1.Pod, node *v1.Node) uint64 {
+	if opts.MpsReuseWeight == 0 || !PodNeedsMps(pod) {
+		return 0
+	}
+	var tenants map[int][]ledger.Reservation
+	if opts.Ledger != nil {
+		tenants = opts.Ledger.CardReservations(node.GetName())
+	}
+	return FittingCardsScore(s, pod, func(i int, card scv.Card) uint64 {
+		if active, ok := filter.CardAnnotation(node, CardMpsActiveAnnotation, i); ok && strings.EqualFold(active, "true") {
+			return 100
+		}
+		for _, r := range tenants[i] {
+			if r.Mps {
+				return 100
+			}
+		}
+		return 0
+	}) * opts.MpsReuseWeight
+}
@@ -0,0 +1,35 @@
+package score
+
+import (
+	"k8s.io/klog"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+)
+
+// Normalize maps raw node scores onto the framework's score range in place.
+func Normalize(scores framework.NodeScoreList) {
+	if len(scores) == 0 {
+		return
+	}
+	var (
+		highest = scores[0].Score
+		lowest  = scores[0].Score
+	)
+	for _, nodeScore := range scores {
+		if nodeScore.Score < lowest {
+			lowest = nodeScore.Score
+		}
+		if nodeScore.Score > highest {
+			highest = nodeScore.Score
+		}
+	}
+
+	if highest == lowest {
+		lowest--
+	}
+
+	// Set Range to [0-100]
+	for i, nodeScore := range scores {
+		scores[i].Score = (nodeScore.Score - lowest) * framework.MaxNodeScore / (highest - lowest)
+		klog.V(3).Infof("node: %v, final Score: %v", scores[i].Name, scores[i].Score)
+	}
+}
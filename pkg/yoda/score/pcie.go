@@ -0,0 +1,46 @@
+package score
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// CardPcieSwitchAnnotation names the PCIe switch each card sits under.
+const CardPcieSwitchAnnotation = "yoda.gpu/card-pcie-switch"
+
+// CalculatePcieSwitchScore rewards nodes that can place a multi-card request
+// with as many of its cards as possible under one PCIe switch. Nodes without
+// switch data score zero.
+func CalculatePcieSwitchScore(opts Options, s *scv.Scv, pod *v1.Pod, node *v1.Node) uint64 {
+	if opts.PcieSwitchWeight == 0 {
+		return 0
+	}
+	ok, number := filter.PodFitsNumber(pod, s)
+	if !ok || number < 2 {
+		return 0
+	}
+	_, memory := filter.PodFitsMemory(number, pod, s)
+	_, clock := filter.PodFitsClock(number, pod, s)
+	groups := map[string]uint{}
+	for i, card := range s.Status.CardList {
+		if !filter.CardFits(memory, clock, card) {
+			continue
+		}
+		if sw, ok := filter.CardAnnotation(node, CardPcieSwitchAnnotation, i); ok {
+			groups[sw]++
+		}
+	}
+	var largest uint
+	for _, n := range groups {
+		if n > largest {
+			largest = n
+		}
+	}
+	if largest > number {
+		largest = number
+	}
+	return uint64(largest*100/number) * opts.PcieSwitchWeight
+}
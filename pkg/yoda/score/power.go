@@ -0,0 +1,35 @@
+package score
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+const (
+	PowerStateAnnotation       = "yoda.gpu/card-power-state"
+	LatencySensitiveAnnotation = "yoda.gpu/latency-sensitive"
+
+	PowerStateActive = "active"
+	PowerStateIdle   = "idle"
+)
+
+// CalculatePowerStateScore rewards cards already out of low-power idle for
+// latency-sensitive pods, and idle cards for batch pods when power saving is on.
+func CalculatePowerStateScore(opts Options, s *scv.Scv, pod *v1.Pod, node *v1.Node) uint64 {
+	want := PowerStateActive
+	if !filter.PodAnnotationIsTrue(pod, LatencySensitiveAnnotation) {
+		if !opts.PowerSaving {
+			return 0
+		}
+		want = PowerStateIdle
+	}
+	return FittingCardsScore(s, pod, func(i int, card scv.Card) uint64 {
+		if state, ok := filter.CardAnnotation(node, PowerStateAnnotation, i); ok && state == want {
+			return 100
+		}
+		return 0
+	}) * opts.PowerStateWeight
+}
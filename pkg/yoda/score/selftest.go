@@ -0,0 +1,87 @@
+package score
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/collection"
+)
+
+// SelfTestCase is a golden scoring case: the normalized score each node is
+// expected to get for the pod, within Tolerance.
+type SelfTestCase struct {
+	Name      string
+	Pod       *v1.Pod
+	Nodes     []SelfTestNode
+	Expected  map[string]int64
+	Tolerance int64
+}
+
+type SelfTestNode struct {
+	Node *v1.Node
+	Scv  scv.Scv
+}
+
+type SelfTestResult struct {
+	Name     string           `json:"name"`
+	Passed   bool             `json:"passed"`
+	Expected map[string]int64 `json:"expected"`
+	Actual   map[string]int64 `json:"actual"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// RunSelfTest scores the golden cases with opts and reports which ones no
+// longer match. The golden values assume the options of the default Args.
+func RunSelfTest(opts Options) []SelfTestResult {
+	results := make([]SelfTestResult, 0, len(GoldenCases))
+	for _, c := range GoldenCases {
+		results = append(results, RunSelfTestCase(opts, c))
+	}
+	return results
+}
+
+func RunSelfTestCase(opts Options, c SelfTestCase) SelfTestResult {
+	result := SelfTestResult{Name: c.Name, Expected: c.Expected, Actual: map[string]int64{}}
+	state := framework.NewCycleState()
+	list := scv.ScvList{}
+	for _, n := range c.Nodes {
+		list.Items = append(list.Items, n.Scv)
+	}
+	if status := collection.CollectMaxValues(state, c.Pod, list); !status.IsSuccess() {
+		result.Error = status.Message()
+		return result
+	}
+	scores := make(framework.NodeScoreList, 0, len(c.Nodes))
+	for i := range c.Nodes {
+		n := c.Nodes[i]
+		info := nodeinfo.NewNodeInfo()
+		if err := info.SetNode(n.Node); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		s, err := CalculateScore(opts, &n.Scv, state, c.Pod, info)
+		if err != nil {
+			result.Error = fmt.Sprintf("node %v: %v", n.Node.GetName(), err)
+			return result
+		}
+		scores = append(scores, framework.NodeScore{Name: n.Node.GetName(), Score: s})
+	}
+	Normalize(scores)
+	result.Passed = true
+	for _, s := range scores {
+		result.Actual[s.Name] = s.Score
+		diff := s.Score - c.Expected[s.Name]
+		if diff < 0 {
+			diff = -diff
+		}
+		if _, ok := c.Expected[s.Name]; !ok || diff > c.Tolerance {
+			result.Passed = false
+		}
+	}
+	return result
+}
@@ -0,0 +1,13 @@
+package score
+
+import "testing"
+
+func TestRunSelfTest(t *testing.T) {
+	for _, r := range RunSelfTest(Options{}) {
+		t.Run(r.Name, func(t *testing.T) {
+			if !r.Passed {
+				t.Errorf("expected %v, got %v %v", r.Expected, r.Actual, r.Error)
+			}
+		})
+	}
+}
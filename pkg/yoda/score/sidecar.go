@@ -0,0 +1,53 @@
+package score
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+const (
+	// SidecarImageAnnotation names the mesh sidecar image the pod will be injected with.
+	SidecarImageAnnotation = "yoda.gpu/sidecar-image"
+	// WarmSidecarsAnnotation lists sidecar images kept warm on the node, comma-separated.
+	WarmSidecarsAnnotation = "yoda.gpu/warm-sidecars"
+)
+
+// SidecarWarm reports whether the node has the sidecar image warm: declared in
+// its annotation, cached in its image list, or used by a pod running there.
+func SidecarWarm(image string, info *nodeinfo.NodeInfo) bool {
+	node := info.Node()
+	if node == nil {
+		return false
+	}
+	image = normalizeImageName(image)
+	for _, warm := range strings.Split(node.GetAnnotations()[WarmSidecarsAnnotation], ",") {
+		if warm = strings.TrimSpace(warm); warm != "" && normalizeImageName(warm) == image {
+			return true
+		}
+	}
+	for _, cached := range node.Status.Images {
+		for _, name := range cached.Names {
+			if normalizeImageName(name) == image {
+				return true
+			}
+		}
+	}
+	for _, pod := range info.Pods() {
+		for _, c := range pod.Spec.Containers {
+			if normalizeImageName(c.Image) == image {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func CalculateSidecarWarmScore(opts Options, pod *v1.Pod, info *nodeinfo.NodeInfo) uint64 {
+	image, ok := pod.GetAnnotations()[SidecarImageAnnotation]
+	if !ok || image == "" || opts.SidecarWarmWeight == 0 || !SidecarWarm(image, info) {
+		return 0
+	}
+	return 100 * opts.SidecarWarmWeight
+}
@@ -0,0 +1,94 @@
+package score
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// CardTemperatureAnnotation holds each card's temperature in degrees Celsius.
+const CardTemperatureAnnotation = "yoda.gpu/card-temperature"
+
+type ThermalPoint struct {
+	Temperature uint64
+	Percent     uint64
+}
+
+// ThermalCurve maps card temperature to the share of nominal performance a
+// card keeps, interpolating linearly between points.
+type ThermalCurve []ThermalPoint
+
+// ParseThermalCurve reads a curve written as "temperature:percent,...", e.g. "70:100,90:60".
+func ParseThermalCurve(value string) (ThermalCurve, error) {
+	var curve ThermalCurve
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid thermal curve point %q", entry)
+		}
+		temperature, err := strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid thermal curve point %q: %v", entry, err)
+		}
+		percent, err := strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil || percent > 100 {
+			return nil, fmt.Errorf("invalid thermal curve point %q: percent must be 0-100", entry)
+		}
+		curve = append(curve, ThermalPoint{Temperature: temperature, Percent: percent})
+	}
+	sort.Slice(curve, func(i, j int) bool {
+		return curve[i].Temperature < curve[j].Temperature
+	})
+	return curve, nil
+}
+
+// Percent returns the performance kept at temperature; cards cooler than the
+// first point keep all of it.
+func (c ThermalCurve) Percent(temperature uint64) uint64 {
+	if len(c) == 0 || temperature <= c[0].Temperature {
+		return 100
+	}
+	for i := 1; i < len(c); i++ {
+		lo, hi := c[i-1], c[i]
+		if temperature > hi.Temperature {
+			continue
+		}
+		span := hi.Temperature - lo.Temperature
+		if span == 0 {
+			return hi.Percent
+		}
+		offset := temperature - lo.Temperature
+		if hi.Percent < lo.Percent {
+			return lo.Percent - (lo.Percent-hi.Percent)*offset/span
+		}
+		return lo.Percent + (hi.Percent-lo.Percent)*offset/span
+	}
+	return c[len(c)-1].Percent
+}
+
+// ThermalDerate returns the percentage of its score the card at index keeps for
+// the pod. Only bandwidth-heavy pods are derated, and cards without a reported
+// temperature keep their full score.
+func ThermalDerate(opts Options, pod *v1.Pod, node *v1.Node, index int) uint64 {
+	if len(opts.ThermalCurve) == 0 || opts.IgnoredMetrics[MetricTemperature] || !filter.PodAnnotationIsTrue(pod, filter.BandwidthHeavyAnnotation) {
+		return 100
+	}
+	value, ok := filter.CardAnnotation(node, CardTemperatureAnnotation, index)
+	if !ok {
+		return 100
+	}
+	temperature, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 100
+	}
+	return opts.ThermalCurve.Percent(temperature)
+}
@@ -0,0 +1,92 @@
+package score
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+)
+
+const (
+	FallbackNone              = ""
+	FallbackLeastRecentlyUsed = "least-recently-used"
+	FallbackRoundRobin        = "round-robin"
+	FallbackRandom            = "random"
+)
+
+// TieBreaker picks one node among those tied at the top after normalization.
+type TieBreaker struct {
+	mu         sync.Mutex
+	policy     string
+	next       int
+	rand       *rand.Rand
+	lastChosen map[string]time.Time
+}
+
+// NewTieBreaker returns a tie breaker for the policy; seed drives the random policy.
+func NewTieBreaker(policy string, seed int64) *TieBreaker {
+	return &TieBreaker{
+		policy:     policy,
+		rand:       rand.New(rand.NewSource(seed)),
+		lastChosen: map[string]time.Time{},
+	}
+}
+
+// Chosen records that a pod was placed on the node, for the least-recently-used policy.
+func (t *TieBreaker) Chosen(node string, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastChosen[node] = at
+}
+
+// Break lowers every top-tied node but the picked one by one point, in place.
+func (t *TieBreaker) Break(scores framework.NodeScoreList) {
+	if t == nil || t.policy == FallbackNone || len(scores) < 2 {
+		return
+	}
+	top := scores[0].Score
+	for _, s := range scores {
+		if s.Score > top {
+			top = s.Score
+		}
+	}
+	var tied []string
+	for _, s := range scores {
+		if s.Score == top {
+			tied = append(tied, s.Name)
+		}
+	}
+	if len(tied) < 2 {
+		return
+	}
+	sort.Strings(tied)
+	picked := t.pick(tied)
+	for i, s := range scores {
+		if s.Score == top && s.Name != picked {
+			scores[i].Score--
+		}
+	}
+}
+
+func (t *TieBreaker) pick(tied []string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch t.policy {
+	case FallbackRoundRobin:
+		picked := tied[t.next%len(tied)]
+		t.next++
+		return picked
+	case FallbackRandom:
+		return tied[t.rand.Intn(len(tied))]
+	default:
+		picked := tied[0]
+		for _, name := range tied[1:] {
+			if t.lastChosen[name].Before(t.lastChosen[picked]) {
+				picked = name
+			}
+		}
+		return picked
+	}
+}
@@ -0,0 +1,89 @@
+package score
+
+import (
+	"testing"
+	"time"
+
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+)
+
+// tiedScores returns a fresh score list with a, b and c tied at the top.
+func tiedScores() framework.NodeScoreList {
+	return framework.NodeScoreList{
+		{Name: "c", Score: 100},
+		{Name: "low", Score: 40},
+		{Name: "a", Score: 100},
+		{Name: "b", Score: 100},
+	}
+}
+
+// winner is the single top-scored node, or "" when the tie remains.
+func winner(scores framework.NodeScoreList) string {
+	var top int64
+	var names []string
+	for _, s := range scores {
+		switch {
+		case s.Score > top:
+			top, names = s.Score, []string{s.Name}
+		case s.Score == top:
+			names = append(names, s.Name)
+		}
+	}
+	if len(names) != 1 {
+		return ""
+	}
+	return names[0]
+}
+
+func TestTieBreakerRoundRobin(t *testing.T) {
+	tb := NewTieBreaker(FallbackRoundRobin, 0)
+	want := []string{"a", "b", "c", "a", "b"}
+	for i, w := range want {
+		scores := tiedScores()
+		tb.Break(scores)
+		if got := winner(scores); got != w {
+			t.Errorf("pod %v: winner %q, want %q", i, got, w)
+		}
+		for _, s := range scores {
+			if s.Name == "low" && s.Score != 40 {
+				t.Errorf("pod %v: untied node changed to %v", i, s.Score)
+			}
+		}
+	}
+}
+
+func TestTieBreakerLeastRecentlyUsed(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		chosen map[string]time.Time
+		want   string
+	}{
+		{name: "never chosen nodes are coldest", chosen: map[string]time.Time{"a": now, "c": now.Add(-time.Hour)}, want: "b"},
+		{name: "oldest placement wins", chosen: map[string]time.Time{"a": now, "b": now.Add(-time.Minute), "c": now.Add(-time.Hour)}, want: "c"},
+		{name: "untied nodes are not considered", chosen: map[string]time.Time{"a": now.Add(-time.Minute), "b": now, "c": now}, want: "a"},
+		{name: "no history picks by name", want: "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := NewTieBreaker(FallbackLeastRecentlyUsed, 0)
+			tb.Chosen("low", now.Add(-24*time.Hour))
+			for node, at := range tt.chosen {
+				tb.Chosen(node, at)
+			}
+			scores := tiedScores()
+			tb.Break(scores)
+			if got := winner(scores); got != tt.want {
+				t.Errorf("winner %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTieBreakerNone(t *testing.T) {
+	scores := tiedScores()
+	NewTieBreaker(FallbackNone, 0).Break(scores)
+	if got := winner(scores); got != "" {
+		t.Errorf("winner %q, want the tie left to the scheduler", got)
+	}
+}
@@ -0,0 +1,35 @@
+package score
+
+import (
+	v1 "k8s.io/api/core/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/collection"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// CalculateVendorBalanceScore steers pods without a vendor requirement to the
+// vendor fleet with less memory in use, scored by how far it trails the busiest fleet.
+func CalculateVendorBalanceScore(opts Options, state *framework.CycleState, pod *v1.Pod, node *v1.Node) uint64 {
+	if opts.VendorBalanceWeight == 0 {
+		return 0
+	}
+	if _, ok := filter.PodVendor(pod); ok {
+		return 0
+	}
+	usage := collection.ReadVendorUsage(state)
+	if len(usage) < 2 {
+		return 0
+	}
+	var busiest uint64
+	for _, u := range usage {
+		if u > busiest {
+			busiest = u
+		}
+	}
+	u, ok := usage[filter.NodeVendor(node)]
+	if !ok {
+		return 0
+	}
+	return (busiest - u) * opts.VendorBalanceWeight
+}
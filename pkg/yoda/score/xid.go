@@ -0,0 +1,34 @@
+package score
+
+import (
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// XidErrorRateAnnotation is the rate of GPU Xid kernel errors on the node, per hour.
+	XidErrorRateAnnotation = "yoda.gpu/xid-errors-per-hour"
+
+	MaxXidErrorRate = 100
+)
+
+// CalculateKernelErrorScore penalizes nodes logging GPU Xid errors. Nodes
+// without a reported rate are treated as neutral.
+func CalculateKernelErrorScore(opts Options, node *v1.Node) float64 {
+	if opts.KernelErrorWeight == 0 {
+		return 0
+	}
+	value, ok := node.GetAnnotations()[XidErrorRateAnnotation]
+	if !ok {
+		return 0
+	}
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || rate <= 0 {
+		return 0
+	}
+	if rate > MaxXidErrorRate {
+		rate = MaxXidErrorRate
+	}
+	return -rate * float64(opts.KernelErrorWeight)
+}
@@ -0,0 +1,130 @@
+package scvcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+)
+
+type ReadMode string
+
+const (
+	// ReadLive always goes to the API server.
+	ReadLive ReadMode = "live"
+	// ReadCache serves an SCV fetched within the TTL and reads live otherwise.
+	ReadCache ReadMode = "cache"
+)
+
+var ErrNoClient = errors.New("SCV client unavailable")
+
+type entry struct {
+	scv     *scv.Scv
+	fetched time.Time
+}
+
+// Cache is the SCV access layer; every read states the consistency it needs.
+type Cache struct {
+	client  client.Client
+	clock   clock.Clock
+	ttl     time.Duration
+	mu      sync.RWMutex
+	entries map[string]entry
+	synced  bool
+}
+
+func New(c client.Client, clk clock.Clock, ttl time.Duration) *Cache {
+	return &Cache{
+		client:  c,
+		clock:   clk,
+		ttl:     ttl,
+		entries: map[string]entry{},
+	}
+}
+
+// Client returns the SCV client, or nil while none could be constructed.
+func (c *Cache) Client() client.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.client
+}
+
+func (c *Cache) SetClient(cl client.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.client = cl
+}
+
+func (c *Cache) Ready() bool {
+	return c.Client() != nil
+}
+
+// Enabled reports whether cached reads can be served at all.
+func (c *Cache) Enabled() bool {
+	return c.ttl > 0
+}
+
+// Synced reports whether a list has filled the cache at least once.
+func (c *Cache) Synced() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.synced
+}
+
+func (c *Cache) Get(ctx context.Context, name string, mode ReadMode, out *scv.Scv) error {
+	if mode == ReadCache {
+		c.mu.RLock()
+		e, ok := c.entries[name]
+		c.mu.RUnlock()
+		if ok && c.clock.Since(e.fetched) < c.ttl {
+			e.scv.DeepCopyInto(out)
+			return nil
+		}
+	}
+	cl := c.Client()
+	if cl == nil {
+		return ErrNoClient
+	}
+	if err := cl.Get(ctx, types.NamespacedName{Name: name}, out); err != nil {
+		return err
+	}
+	c.store(out)
+	return nil
+}
+
+// List always reads live and refreshes the cache with the result.
+func (c *Cache) List(ctx context.Context, list *scv.ScvList) error {
+	cl := c.Client()
+	if cl == nil {
+		return ErrNoClient
+	}
+	if err := cl.List(ctx, list); err != nil {
+		return err
+	}
+	for i := range list.Items {
+		c.store(&list.Items[i])
+	}
+	c.mu.Lock()
+	c.synced = true
+	c.mu.Unlock()
+	return nil
+}
+
+// Invalidate drops the cached SCV of a node after Yoda wrote to it.
+func (c *Cache) Invalidate(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, name)
+}
+
+func (c *Cache) store(s *scv.Scv) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[s.GetName()] = entry{scv: s.DeepCopy(), fetched: c.clock.Now()}
+}
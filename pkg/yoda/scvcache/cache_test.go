@@ -0,0 +1,111 @@
+package scvcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+)
+
+func testClient(t *testing.T, free uint64) client.Client {
+	s := runtime.NewScheme()
+	if err := scv.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	return fake.NewFakeClientWithScheme(s, &scv.Scv{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Status:     scv.ScvStatus{FreeMemorySum: free},
+	})
+}
+
+// setFree changes the SCV on the API server behind the cache's back.
+func setFree(t *testing.T, c client.Client, free uint64) {
+	s := &scv.Scv{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "node"}, s); err != nil {
+		t.Fatal(err)
+	}
+	s.Status.FreeMemorySum = free
+	if err := c.Update(context.Background(), s); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name string
+		// advance moves the clock between warming the cache and the read.
+		advance    time.Duration
+		invalidate bool
+		mode       ReadMode
+		want       uint64
+	}{
+		{name: "cache read within the TTL serves the cached SCV", mode: ReadCache, want: 1000},
+		{name: "live read bypasses the cache", mode: ReadLive, want: 2000},
+		{name: "cache read after the TTL reads live", advance: 10 * time.Second, mode: ReadCache, want: 2000},
+		{name: "cache read after a write by Yoda reads live", invalidate: true, mode: ReadCache, want: 2000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			clk := clock.NewFakeClock(time.Now())
+			cl := testClient(t, 1000)
+			c := New(cl, clk, 5*time.Second)
+			if err := c.Get(ctx, "node", ReadLive, &scv.Scv{}); err != nil {
+				t.Fatal(err)
+			}
+			setFree(t, cl, 2000)
+			clk.Step(tt.advance)
+			if tt.invalidate {
+				c.Invalidate("node")
+			}
+			s := &scv.Scv{}
+			if err := c.Get(ctx, "node", tt.mode, s); err != nil {
+				t.Fatal(err)
+			}
+			if s.Status.FreeMemorySum != tt.want {
+				t.Errorf("Get() free memory = %v, want %v", s.Status.FreeMemorySum, tt.want)
+			}
+		})
+	}
+}
+
+func TestLiveReadRefreshesCache(t *testing.T) {
+	ctx := context.Background()
+	cl := testClient(t, 1000)
+	c := New(cl, clock.NewFakeClock(time.Now()), time.Minute)
+	// Filter warms the cache, then the SCV changes before Reserve reads live.
+	if err := c.Get(ctx, "node", ReadCache, &scv.Scv{}); err != nil {
+		t.Fatal(err)
+	}
+	setFree(t, cl, 2000)
+	reserve := &scv.Scv{}
+	if err := c.Get(ctx, "node", ReadLive, reserve); err != nil {
+		t.Fatal(err)
+	}
+	// A later cached read must not go back to data older than Reserve's.
+	filter := &scv.Scv{}
+	if err := c.Get(ctx, "node", ReadCache, filter); err != nil {
+		t.Fatal(err)
+	}
+	if reserve.Status.FreeMemorySum != 2000 || filter.Status.FreeMemorySum != 2000 {
+		t.Errorf("live read saw %v and the next cached read %v, want 2000 for both", reserve.Status.FreeMemorySum, filter.Status.FreeMemorySum)
+	}
+}
+
+func TestNoClient(t *testing.T) {
+	c := New(nil, clock.RealClock{}, time.Minute)
+	if err := c.Get(context.Background(), "node", ReadLive, &scv.Scv{}); err != ErrNoClient {
+		t.Errorf("Get() error = %v, want %v", err, ErrNoClient)
+	}
+	if c.Ready() {
+		t.Error("Ready() without a client")
+	}
+}
@@ -0,0 +1,78 @@
+package yoda
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+)
+
+const (
+	// ScvClientFailureFail refuses to start the plugin without an SCV client.
+	ScvClientFailureFail = "fail"
+	// ScvClientFailurePassthrough schedules without GPU constraints.
+	ScvClientFailurePassthrough = "passthrough"
+	// ScvClientFailureRetry passes through while retrying in the background.
+	ScvClientFailureRetry = "retry"
+
+	ScvClientRetryInterval = 30 * time.Second
+)
+
+// passthrough reports whether GPU logic is disabled because there is no SCV client.
+func (y *Yoda) passthrough() bool {
+	return !y.scvs.Ready()
+}
+
+func (y *Yoda) retryScvClient() {
+	go func() {
+		_ = wait.PollInfinite(ScvClientRetryInterval, func() (bool, error) {
+			c, err := NewScvClient(y.args)
+			if err != nil {
+				return false, nil
+			}
+			y.scvs.SetClient(c)
+			klog.Infof("SCV client constructed, GPU scheduling enabled")
+			return true, nil
+		})
+	}()
+}
+
+// ParseScvGroupVersionKind reads a kind written as group/version/Kind.
+func ParseScvGroupVersionKind(value string) (schema.GroupVersionKind, error) {
+	i := strings.LastIndex(value, "/")
+	if i < 0 || i == len(value)-1 {
+		return schema.GroupVersionKind{}, fmt.Errorf("invalid SCV kind %q, want group/version/Kind", value)
+	}
+	gv, err := schema.ParseGroupVersion(value[:i])
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+	if gv.Group == "" || gv.Version == "" {
+		return schema.GroupVersionKind{}, fmt.Errorf("invalid SCV kind %q, want group/version/Kind", value)
+	}
+	return gv.WithKind(value[i+1:]), nil
+}
+
+// addScvToScheme registers the SCV types, under an alternate kind when a fork
+// of the CRD serves the same schema elsewhere. Only the configured kind is
+// registered so the client resolves the SCV types to it unambiguously.
+func addScvToScheme(s *runtime.Scheme, kind string) error {
+	if kind == "" {
+		return scv.AddToScheme(s)
+	}
+	gvk, err := ParseScvGroupVersionKind(kind)
+	if err != nil {
+		return err
+	}
+	s.AddKnownTypeWithName(gvk, &scv.Scv{})
+	s.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &scv.ScvList{})
+	metav1.AddToGroupVersion(s, gvk.GroupVersion())
+	return nil
+}
@@ -0,0 +1,129 @@
+package yoda
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	nodeinfosnapshot "k8s.io/kubernetes/pkg/scheduler/nodeinfo/snapshot"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	scv "github.com/NJUPT-ISL/SCV/api/v1"
+)
+
+func TestOnScvClientFailure(t *testing.T) {
+	// Outside a cluster and without a kubeconfig no SCV client can be built.
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		t.Skip("running in a cluster, the SCV client can be constructed")
+	}
+	tests := []struct {
+		policy    string
+		wantStart bool
+	}{
+		{policy: ScvClientFailureFail, wantStart: false},
+		{policy: ScvClientFailurePassthrough, wantStart: true},
+		{policy: ScvClientFailureRetry, wantStart: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			ctx := context.Background()
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod", Labels: map[string]string{"scv/number": "8"}}}
+			node := testNode("node")
+			cs := fake.NewSimpleClientset(pod, node)
+			handle := &fakeHandle{
+				clientSet: cs,
+				snapshot:  nodeinfosnapshot.NewSnapshot(nodeinfosnapshot.CreateNodeInfoMap(nil, []*v1.Node{node})),
+				informers: informers.NewSharedInformerFactory(cs, 0),
+			}
+			config := &runtime.Unknown{Raw: []byte(`{"onScvClientFailure":"` + tt.policy + `"}`)}
+			plugin, err := New(config, handle)
+			if (err == nil) != tt.wantStart {
+				t.Fatalf("New() error = %v, want start %v", err, tt.wantStart)
+			}
+			if !tt.wantStart {
+				return
+			}
+			y := plugin.(*Yoda)
+			if !y.passthrough() {
+				t.Fatal("plugin is not in passthrough without an SCV client")
+			}
+			// The pod asks for more cards than any node has, but GPU constraints are off.
+			nodeInfo, err := handle.SnapshotSharedLister().NodeInfos().Get("node")
+			if err != nil {
+				t.Fatal(err)
+			}
+			state := framework.NewCycleState()
+			if status := y.PreFilter(ctx, state, pod); !status.IsSuccess() {
+				t.Errorf("PreFilter() = %v", status.Message())
+			}
+			if status := y.Filter(ctx, state, pod, nodeInfo); !status.IsSuccess() {
+				t.Errorf("Filter() = %v", status.Message())
+			}
+			if score, status := y.Score(ctx, state, pod, "node"); !status.IsSuccess() || score != 0 {
+				t.Errorf("Score() = %v, %v, want a neutral 0", score, status.Message())
+			}
+			if status := y.Reserve(ctx, state, pod, "node"); !status.IsSuccess() {
+				t.Errorf("Reserve() = %v", status.Message())
+			}
+			if status := y.PreBind(ctx, state, pod, "node"); !status.IsSuccess() {
+				t.Errorf("PreBind() = %v", status.Message())
+			}
+		})
+	}
+}
+
+func TestApplyClientLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		qps       float32
+		burst     int
+		wantQPS   float32
+		wantBurst int
+	}{
+		{name: "configured", qps: 50, burst: 100, wantQPS: 50, wantBurst: 100},
+		{name: "zero falls back to defaults", wantQPS: rest.DefaultQPS, wantBurst: rest.DefaultBurst},
+		{name: "negative falls back to defaults", qps: -1, burst: -5, wantQPS: rest.DefaultQPS, wantBurst: rest.DefaultBurst},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := &Args{ScvClientQPS: tt.qps, ScvClientBurst: tt.burst}
+			args.SetDefaults()
+			config := &rest.Config{}
+			ApplyClientLimits(config, args)
+			if config.QPS != tt.wantQPS || config.Burst != tt.wantBurst {
+				t.Errorf("config QPS %v burst %v, want %v and %v", config.QPS, config.Burst, tt.wantQPS, tt.wantBurst)
+			}
+		})
+	}
+}
+
+func TestRenamedScvKind(t *testing.T) {
+	const kind = "gpu.example.com/v1alpha1/GpuNode"
+	s := runtime.NewScheme()
+	if err := addScvToScheme(s, kind); err != nil {
+		t.Fatal(err)
+	}
+	gvks, _, err := s.ObjectKinds(&scv.Scv{})
+	if err != nil || len(gvks) != 1 || gvks[0].String() != "gpu.example.com/v1alpha1, Kind=GpuNode" {
+		t.Fatalf("SCV kinds = %v, %v, want only the forked kind", gvks, err)
+	}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod", Labels: map[string]string{"scv/memory": "6000"}}}
+	y := newTestYoda(t, pod, []*v1.Node{testNode("roomy"), testNode("small"), testNode("full")})
+	y.scvs.SetClient(fakeclient.NewFakeClientWithScheme(s,
+		testScv("roomy", testCard(12000, 12000)), testScv("small", testCard(8000, 8000)), testScv("full", testCard(4000, 8000))))
+
+	fits, scores := filterAndScore(t, y, pod, "roomy", "small", "full")
+	if !fits["roomy"] || !fits["small"] || fits["full"] {
+		t.Errorf("Filter() passes %v, want roomy and small", fits)
+	}
+	if scores["roomy"] <= scores["small"] {
+		t.Errorf("Score() = %v, want the roomy node ahead", scores)
+	}
+}
@@ -0,0 +1,40 @@
+package yoda
+
+import (
+	v1 "k8s.io/api/core/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+const (
+	SkipAnnotation = "yoda.gpu/skip"
+	SkipStateKey   = "SkipFilter"
+)
+
+// skipFilter marks a pod whose Filter calls can pass without looking at the
+// node. The v1alpha1 framework only honours a Skip status from Bind, so
+// PreFilter records the decision in the cycle state instead.
+type skipFilter struct{}
+
+func (s skipFilter) Clone() framework.StateData {
+	return s
+}
+
+// shouldSkipFilter reports whether Filter has nothing to check for the pod.
+// Pods without GPUs, which include pods stating no requirement when
+// unrequestedMeansNoGpu is set, are still filtered under strict GPU node
+// isolation.
+func (y *Yoda) shouldSkipFilter(pod *v1.Pod) bool {
+	if filter.PodAnnotationIsTrue(pod, SkipAnnotation) {
+		return true
+	}
+	return filter.PodRequestsNoGpu(pod) && !y.args.StrictGpuNodeIsolation
+}
+
+func filterSkipped(state *framework.CycleState) bool {
+	state.RLock()
+	defer state.RUnlock()
+	_, err := state.Read(SkipStateKey)
+	return err == nil
+}
@@ -0,0 +1,105 @@
+package yoda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/stats"
+)
+
+func TestShouldSkipFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		unrequested bool
+		strict      bool
+		wantSkip    bool
+		wantFits    bool
+	}{
+		{name: "explicit zero cards", labels: map[string]string{"scv/number": "0"}, wantSkip: true, wantFits: true},
+		{name: "no requirement when unrequested pods get no GPU", unrequested: true, wantSkip: true, wantFits: true},
+		{name: "no requirement by default", wantSkip: false, wantFits: true},
+		{name: "no requirement under strict isolation", unrequested: true, strict: true, wantSkip: false},
+		{name: "skip annotation", labels: map[string]string{"scv/memory": "4000"}, annotations: map[string]string{SkipAnnotation: "true"}, wantSkip: true, wantFits: true},
+		{name: "GPU pod", labels: map[string]string{"scv/memory": "4000"}, unrequested: true, wantSkip: false},
+		{name: "GPU pod by annotation", annotations: map[string]string{filter.ModelSizeAnnotation: "4GB"}, unrequested: true, wantSkip: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod", Labels: tt.labels, Annotations: tt.annotations}}
+			// The only card is full, so a processed memory request cannot pass Filter.
+			y := newTestYoda(t, pod, []*v1.Node{testNode("node")}, testScv("node", testCard(0, 8000)))
+			y.args.UnrequestedMeansNoGpu = tt.unrequested
+			y.args.StrictGpuNodeIsolation = tt.strict
+			y.predicates = y.args.Predicates(y.ledger)
+			y.setRequirementParsers()
+			defer filter.SetUnrequestedNoGpu(false)
+			defer filter.ResetRequirementParsers()
+
+			state := framework.NewCycleState()
+			if status := y.PreFilter(ctx, state, pod); !status.IsSuccess() {
+				t.Fatalf("PreFilter() = %v", status.Message())
+			}
+			if skipped := filterSkipped(state); skipped != tt.wantSkip {
+				t.Fatalf("filterSkipped() = %v, want %v", skipped, tt.wantSkip)
+			}
+			nodeInfo, err := y.handle.SnapshotSharedLister().NodeInfos().Get("node")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status := y.Filter(ctx, state, pod, nodeInfo); status.IsSuccess() != tt.wantFits {
+				t.Errorf("Filter() = %v, want success %v", status.Message(), tt.wantFits)
+			}
+		})
+	}
+}
+
+func TestSkippedFilterHonoursMaintenanceAndQuarantine(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		maintenance bool
+		quarantined bool
+		wantFits    bool
+	}{
+		{name: "skipped GPU pod", labels: map[string]string{"scv/memory": "4000"}, wantFits: true},
+		{name: "skipped GPU pod in maintenance mode", labels: map[string]string{"scv/memory": "4000"}, maintenance: true, wantFits: false},
+		{name: "skipped GPU pod on a quarantined node", labels: map[string]string{"scv/memory": "4000"}, quarantined: true, wantFits: false},
+		{name: "skipped non-GPU pod in maintenance mode", labels: map[string]string{"scv/number": "0"}, maintenance: true, wantFits: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod", Labels: tt.labels, Annotations: map[string]string{SkipAnnotation: "true"}}}
+			y := newTestYoda(t, pod, []*v1.Node{testNode("node")}, testScv("node", testCard(8000, 8000)))
+			y.SetMaintenanceMode(tt.maintenance)
+			y.quarantine = stats.NewQuarantine(y.clock, time.Hour, 1, time.Hour)
+			if tt.quarantined {
+				y.quarantine.Record("node")
+			}
+
+			state := framework.NewCycleState()
+			if status := y.PreFilter(ctx, state, pod); !status.IsSuccess() {
+				t.Fatalf("PreFilter() = %v", status.Message())
+			}
+			if !filterSkipped(state) {
+				t.Fatal("filterSkipped() = false, want true")
+			}
+			nodeInfo, err := y.handle.SnapshotSharedLister().NodeInfos().Get("node")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status := y.Filter(ctx, state, pod, nodeInfo); status.IsSuccess() != tt.wantFits {
+				t.Errorf("Filter() = %v, want success %v", status.Message(), tt.wantFits)
+			}
+		})
+	}
+}
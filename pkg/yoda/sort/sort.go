@@ -5,8 +5,14 @@ import (
 	"strconv"
 )
 
+// Less orders pods by scv/priority, highest first. Pods of equal priority are
+// ordered by UID so the queue order is total and the same on every run.
 func Less(podInfo1, podInfo2 *framework.PodInfo) bool {
-	return GetPodPriority(podInfo1) > GetPodPriority(podInfo2)
+	p1, p2 := GetPodPriority(podInfo1), GetPodPriority(podInfo2)
+	if p1 != p2 {
+		return p1 > p2
+	}
+	return podInfo1.Pod.GetUID() < podInfo2.Pod.GetUID()
 }
 
 func GetPodPriority(podInfo *framework.PodInfo) int {
@@ -0,0 +1,62 @@
+package sort
+
+import (
+	gosort "sort"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+)
+
+func testPodInfo(uid, priority string) *framework.PodInfo {
+	labels := map[string]string{}
+	if priority != "" {
+		labels["scv/priority"] = priority
+	}
+	return &framework.PodInfo{Pod: &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: uid, UID: types.UID(uid), Labels: labels}}}
+}
+
+func TestLess(t *testing.T) {
+	tests := []struct {
+		name string
+		pods []*framework.PodInfo
+		want []string
+	}{
+		{
+			name: "higher priority first",
+			pods: []*framework.PodInfo{testPodInfo("a", "1"), testPodInfo("b", "5"), testPodInfo("c", "")},
+			want: []string{"b", "a", "c"},
+		},
+		{
+			name: "equal priority ordered by UID",
+			pods: []*framework.PodInfo{testPodInfo("c", "3"), testPodInfo("a", "3"), testPodInfo("b", "3")},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "missing and invalid priorities tie at zero",
+			pods: []*framework.PodInfo{testPodInfo("b", ""), testPodInfo("c", "high"), testPodInfo("a", "0")},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "UID breaks ties within each priority",
+			pods: []*framework.PodInfo{testPodInfo("d", "1"), testPodInfo("b", "2"), testPodInfo("c", "1"), testPodInfo("a", "2")},
+			want: []string{"a", "b", "c", "d"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Every rotation of the input must give the same order.
+			for i := range tt.pods {
+				pods := append(append([]*framework.PodInfo(nil), tt.pods[i:]...), tt.pods[:i]...)
+				gosort.Slice(pods, func(a, b int) bool { return Less(pods[a], pods[b]) })
+				for j, p := range pods {
+					if p.Pod.Name != tt.want[j] {
+						t.Fatalf("rotation %v: position %v = %v, want %v", i, j, p.Pod.Name, tt.want[j])
+					}
+				}
+			}
+		})
+	}
+}
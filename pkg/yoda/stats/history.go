@@ -0,0 +1,91 @@
+package stats
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+const (
+	MaxHistorySignatures = 1024
+	MaxHistoryNodes      = 16
+)
+
+// PlacementHistory remembers on which nodes each job signature recently ran
+// successfully, bounded in size and age.
+type PlacementHistory struct {
+	mu      sync.Mutex
+	clock   clock.Clock
+	ttl     time.Duration
+	entries map[string]map[string]time.Time
+}
+
+func NewPlacementHistory(c clock.Clock, ttl time.Duration) *PlacementHistory {
+	return &PlacementHistory{
+		clock:   c,
+		ttl:     ttl,
+		entries: map[string]map[string]time.Time{},
+	}
+}
+
+func (h *PlacementHistory) Record(signature, node string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.clock.Now()
+	nodes, ok := h.entries[signature]
+	if !ok {
+		if len(h.entries) >= MaxHistorySignatures {
+			h.evictOldest()
+		}
+		nodes = map[string]time.Time{}
+		h.entries[signature] = nodes
+	}
+	if _, ok := nodes[node]; !ok && len(nodes) >= MaxHistoryNodes {
+		oldest := ""
+		for n, t := range nodes {
+			if oldest == "" || t.Before(nodes[oldest]) {
+				oldest = n
+			}
+		}
+		delete(nodes, oldest)
+	}
+	nodes[node] = now
+}
+
+// Succeeded reports whether the signature ran successfully on the node within the TTL.
+func (h *PlacementHistory) Succeeded(signature, node string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	t, ok := h.entries[signature][node]
+	if !ok {
+		return false
+	}
+	if h.clock.Since(t) >= h.ttl {
+		delete(h.entries[signature], node)
+		if len(h.entries[signature]) == 0 {
+			delete(h.entries, signature)
+		}
+		return false
+	}
+	return true
+}
+
+func (h *PlacementHistory) evictOldest() {
+	var (
+		oldest string
+		latest time.Time
+	)
+	for signature, nodes := range h.entries {
+		var last time.Time
+		for _, t := range nodes {
+			if t.After(last) {
+				last = t
+			}
+		}
+		if oldest == "" || last.Before(latest) {
+			oldest, latest = signature, last
+		}
+	}
+	delete(h.entries, oldest)
+}
@@ -0,0 +1,79 @@
+package stats
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// Quarantine holds back keys that saw threshold events within the window
+// until cooldown has passed.
+type Quarantine struct {
+	mu        sync.Mutex
+	clock     clock.Clock
+	events    *Window
+	threshold uint64
+	cooldown  time.Duration
+	until     map[string]time.Time
+}
+
+func NewQuarantine(c clock.Clock, window time.Duration, threshold uint64, cooldown time.Duration) *Quarantine {
+	return &Quarantine{
+		clock:     c,
+		events:    NewWindow(c, window, time.Minute),
+		threshold: threshold,
+		cooldown:  cooldown,
+		until:     map[string]time.Time{},
+	}
+}
+
+// Record counts an event for key and reports whether it put key into quarantine.
+// Events while key is quarantined are not counted, and entering quarantine
+// starts the count over, so a released key needs threshold new events again.
+func (q *Quarantine) Record(key string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.quarantined(key) {
+		return false
+	}
+	q.events.Add(key)
+	if q.events.Counts()[key] < q.threshold {
+		return false
+	}
+	q.events.ResetKey(key)
+	q.until[key] = q.clock.Now().Add(q.cooldown)
+	return true
+}
+
+func (q *Quarantine) Quarantined(key string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.quarantined(key)
+}
+
+func (q *Quarantine) quarantined(key string) bool {
+	until, ok := q.until[key]
+	if !ok {
+		return false
+	}
+	if !q.clock.Now().Before(until) {
+		delete(q.until, key)
+		return false
+	}
+	return true
+}
+
+// Keys returns the quarantined keys and when each is released.
+func (q *Quarantine) Keys() map[string]time.Time {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.clock.Now()
+	keys := map[string]time.Time{}
+	for key, until := range q.until {
+		if now.Before(until) {
+			keys[key] = until
+		}
+	}
+	return keys
+}
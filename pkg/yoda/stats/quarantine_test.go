@@ -0,0 +1,105 @@
+package stats
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestQuarantine(t *testing.T) {
+	type step struct {
+		advance time.Duration
+		oom     bool
+		// wantEntered is whether this OOM put the node into quarantine.
+		wantEntered     bool
+		wantQuarantined bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "quarantined at the threshold",
+			steps: []step{
+				{oom: true, wantQuarantined: false},
+				{oom: true, wantQuarantined: false},
+				{oom: true, wantEntered: true, wantQuarantined: true},
+			},
+		},
+		{
+			name: "events outside the window do not add up",
+			steps: []step{
+				{oom: true},
+				{oom: true},
+				{advance: 31 * time.Minute, oom: true, wantQuarantined: false},
+			},
+		},
+		{
+			name: "released after the cooldown",
+			steps: []step{
+				{oom: true},
+				{oom: true},
+				{oom: true, wantEntered: true, wantQuarantined: true},
+				{advance: 59 * time.Minute, wantQuarantined: true},
+				{advance: time.Minute, wantQuarantined: false},
+			},
+		},
+		{
+			name: "a released node needs the threshold again",
+			steps: []step{
+				{oom: true},
+				{oom: true},
+				{oom: true, wantEntered: true, wantQuarantined: true},
+				{advance: 10 * time.Minute, oom: true, wantQuarantined: true},
+				{advance: 50 * time.Minute, wantQuarantined: false},
+				{oom: true, wantQuarantined: false},
+				{oom: true, wantQuarantined: false},
+				{oom: true, wantEntered: true, wantQuarantined: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := clock.NewFakeClock(time.Now())
+			q := NewQuarantine(c, 30*time.Minute, 3, time.Hour)
+			for i, s := range tt.steps {
+				c.Step(s.advance)
+				if s.oom {
+					if entered := q.Record("node"); entered != s.wantEntered {
+						t.Errorf("step %v: Record() = %v, want %v", i, entered, s.wantEntered)
+					}
+				}
+				if got := q.Quarantined("node"); got != s.wantQuarantined {
+					t.Errorf("step %v: Quarantined() = %v, want %v", i, got, s.wantQuarantined)
+				}
+			}
+		})
+	}
+}
+
+func TestQuarantineConcurrentRecords(t *testing.T) {
+	c := clock.NewFakeClock(time.Now())
+	q := NewQuarantine(c, 30*time.Minute, 3, time.Hour)
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		entered int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if q.Record("node") {
+				mu.Lock()
+				entered++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if entered != 1 {
+		t.Errorf("%v records put the node into quarantine, want 1", entered)
+	}
+}
@@ -0,0 +1,30 @@
+package stats
+
+type PredicateRejection struct {
+	Count uint64  `json:"count"`
+	Ratio float64 `json:"ratio"`
+}
+
+type RejectionSummary struct {
+	Window     string                        `json:"window"`
+	Total      uint64                        `json:"total"`
+	Predicates map[string]PredicateRejection `json:"predicates"`
+}
+
+func SummarizeRejections(w *Window) RejectionSummary {
+	counts := w.Counts()
+	summary := RejectionSummary{
+		Window:     w.Size().String(),
+		Predicates: map[string]PredicateRejection{},
+	}
+	for _, count := range counts {
+		summary.Total += count
+	}
+	for name, count := range counts {
+		summary.Predicates[name] = PredicateRejection{
+			Count: count,
+			Ratio: float64(count) / float64(summary.Total),
+		}
+	}
+	return summary
+}
@@ -0,0 +1,53 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestSummarizeRejections(t *testing.T) {
+	fake := clock.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	w := NewWindow(fake, time.Hour, time.Minute)
+	add := func(key string, n int) {
+		for i := 0; i < n; i++ {
+			w.Add(key)
+		}
+	}
+	add("memory", 6)
+	fake.Step(30 * time.Minute)
+	add("clock", 2)
+	fake.Step(20 * time.Minute)
+	add("number", 2)
+
+	summary := SummarizeRejections(w)
+	want := map[string]PredicateRejection{
+		"memory": {Count: 6, Ratio: 0.6},
+		"clock":  {Count: 2, Ratio: 0.2},
+		"number": {Count: 2, Ratio: 0.2},
+	}
+	if summary.Total != 10 || !reflect.DeepEqual(summary.Predicates, want) {
+		t.Errorf("summary = %+v, want total 10 and %v", summary, want)
+	}
+	if summary.Window != time.Hour.String() {
+		t.Errorf("window = %v, want %v", summary.Window, time.Hour)
+	}
+
+	// The memory rejections leave the window an hour after they were counted.
+	fake.Step(15 * time.Minute)
+	summary = SummarizeRejections(w)
+	want = map[string]PredicateRejection{
+		"clock":  {Count: 2, Ratio: 0.5},
+		"number": {Count: 2, Ratio: 0.5},
+	}
+	if summary.Total != 4 || !reflect.DeepEqual(summary.Predicates, want) {
+		t.Errorf("summary after expiry = %+v, want total 4 and %v", summary, want)
+	}
+
+	w.Reset()
+	if summary = SummarizeRejections(w); summary.Total != 0 || len(summary.Predicates) != 0 {
+		t.Errorf("summary after reset = %+v, want it empty", summary)
+	}
+}
@@ -0,0 +1,62 @@
+package stats
+
+import (
+	"sync"
+	"time"
+)
+
+type ScoreSample struct {
+	Time  time.Time `json:"time"`
+	Pod   string    `json:"pod"`
+	Score int64     `json:"score"`
+}
+
+// ScoreHistory keeps the last size scores computed for each node.
+type ScoreHistory struct {
+	mu    sync.Mutex
+	size  int
+	nodes map[string]*scoreRing
+}
+
+type scoreRing struct {
+	samples []ScoreSample
+	next    int
+	full    bool
+}
+
+func NewScoreHistory(size int) *ScoreHistory {
+	if size < 1 {
+		size = 1
+	}
+	return &ScoreHistory{size: size, nodes: map[string]*scoreRing{}}
+}
+
+func (h *ScoreHistory) Record(node string, sample ScoreSample) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r, ok := h.nodes[node]
+	if !ok {
+		r = &scoreRing{samples: make([]ScoreSample, h.size)}
+		h.nodes[node] = r
+	}
+	r.samples[r.next] = sample
+	r.next = (r.next + 1) % h.size
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Samples returns each node's recorded scores, oldest first.
+func (h *ScoreHistory) Samples() map[string][]ScoreSample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	samples := map[string][]ScoreSample{}
+	for node, r := range h.nodes {
+		var s []ScoreSample
+		if r.full {
+			s = append(s, r.samples[r.next:]...)
+		}
+		samples[node] = append(s, r.samples[:r.next]...)
+	}
+	return samples
+}
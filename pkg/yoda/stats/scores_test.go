@@ -0,0 +1,41 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScoreHistory(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		recorded []int64
+		want     []int64
+	}{
+		{name: "below capacity", size: 3, recorded: []int64{1, 2}, want: []int64{1, 2}},
+		{name: "exactly full", size: 3, recorded: []int64{1, 2, 3}, want: []int64{1, 2, 3}},
+		{name: "oldest evicted", size: 3, recorded: []int64{1, 2, 3, 4, 5}, want: []int64{3, 4, 5}},
+		{name: "wrapped more than once", size: 2, recorded: []int64{1, 2, 3, 4, 5, 6, 7}, want: []int64{6, 7}},
+		{name: "size below one keeps the last score", size: 0, recorded: []int64{1, 2}, want: []int64{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewScoreHistory(tt.size)
+			for _, s := range tt.recorded {
+				h.Record("node", ScoreSample{Score: s})
+				h.Record("other", ScoreSample{Score: -s})
+			}
+			samples := h.Samples()
+			var got []int64
+			for _, s := range samples["node"] {
+				got = append(got, s.Score)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Samples() = %v, want %v", got, tt.want)
+			}
+			if len(samples["other"]) != len(tt.want) {
+				t.Errorf("other node kept %v samples, want %v", len(samples["other"]), len(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package stats
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// Window counts events per key over a sliding time window made of fixed-size buckets.
+type Window struct {
+	mu      sync.Mutex
+	clock   clock.Clock
+	size    time.Duration
+	bucket  time.Duration
+	buckets []bucket
+}
+
+type bucket struct {
+	epoch  int64
+	counts map[string]uint64
+}
+
+func NewWindow(c clock.Clock, size, bucketSize time.Duration) *Window {
+	n := int(size / bucketSize)
+	if n < 1 {
+		n = 1
+	}
+	return &Window{
+		clock:   c,
+		size:    time.Duration(n) * bucketSize,
+		bucket:  bucketSize,
+		buckets: make([]bucket, n),
+	}
+}
+
+func (w *Window) Size() time.Duration {
+	return w.size
+}
+
+func (w *Window) Add(key string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	epoch := w.epoch()
+	b := &w.buckets[epoch%int64(len(w.buckets))]
+	if b.epoch != epoch || b.counts == nil {
+		b.epoch = epoch
+		b.counts = map[string]uint64{}
+	}
+	b.counts[key]++
+}
+
+func (w *Window) Counts() map[string]uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	epoch := w.epoch()
+	counts := map[string]uint64{}
+	for _, b := range w.buckets {
+		if b.counts == nil || epoch-b.epoch >= int64(len(w.buckets)) {
+			continue
+		}
+		for key, count := range b.counts {
+			counts[key] += count
+		}
+	}
+	return counts
+}
+
+func (w *Window) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buckets = make([]bucket, len(w.buckets))
+}
+
+// ResetKey forgets the events counted for key.
+func (w *Window) ResetKey(key string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, b := range w.buckets {
+		delete(b.counts, key)
+	}
+}
+
+func (w *Window) epoch() int64 {
+	return w.clock.Now().UnixNano() / int64(w.bucket)
+}
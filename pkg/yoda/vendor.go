@@ -0,0 +1,23 @@
+package yoda
+
+import (
+	"k8s.io/klog"
+
+	"github.com/NJUPT-ISL/Yoda-Scheduler/pkg/yoda/filter"
+)
+
+// nodeVendors maps every node in the snapshot to its GPU vendor.
+func (y *Yoda) nodeVendors() map[string]string {
+	vendors := map[string]string{}
+	infos, err := y.handle.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		klog.Errorf("List NodeInfos Error: %v", err)
+		return vendors
+	}
+	for _, info := range infos {
+		if node := info.Node(); node != nil {
+			vendors[node.GetName()] = filter.NodeVendor(node)
+		}
+	}
+	return vendors
+}